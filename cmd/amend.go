@@ -27,7 +27,8 @@ if the date is not decent.`,
 		r := decentContext.gitRepo
 		s := *decentContext.schedule
 
-		commit, err := amend(r, &s)
+		strategy, _ := cmd.Flags().GetString("strategy")
+		commit, err := amend(r, &s, strategy)
 		if err != nil {
 			return err
 		}
@@ -54,7 +55,12 @@ if the date is not decent.`,
 	},
 }
 
-func amend(repo *internal.GitRepo, schedule *config.Schedule) (*internal.Commit, error) {
+func amend(repo *internal.GitRepo, schedule *config.Schedule, strategy string) (*internal.Commit, error) {
+	amender, err := internal.NewAmender(strategy, *schedule)
+	if err != nil {
+		return nil, err
+	}
+
 	log, err := repo.LogWithRevision("-2")
 	if err != nil {
 		return nil, u.WrapE("couldn't get log from repo", err)
@@ -67,20 +73,34 @@ func amend(repo *internal.GitRepo, schedule *config.Schedule) (*internal.Commit,
 	fmt.Println(ui.InfoStyle.Styled("Schedule:"))
 	ui.PrintSchedule(*schedule)
 	fmt.Println()
+	if schedule.LeavesCommits() {
+		return nil, nil
+	}
 
 	var lastRealDate *time.Time = nil
 	var lastDate *time.Time = nil
 	if len(log) > 1 {
 		lastDate = &log[0].Date
-		//TODO: Change this to fetch the date from the saved file
 		lastRealDate = lastDate
+		//The previous commit might have been amended, its real date is noted
+		original, err := repo.OriginalDates(log[0].Hash)
+		if err != nil {
+			return nil, u.WrapE("couldn't get the original dates", err)
+		}
+		if original != nil {
+			lastRealDate = &original.Author
+		}
 	}
 
 	commit := log[1]
-	amended := internal.Amend(commit.Date, lastDate, lastRealDate, 0, *schedule)
+	amended := amender.Amend(internal.Batch{
+		Dates:        []time.Time{commit.Date},
+		LastDate:     lastDate,
+		LastRealDate: lastRealDate,
+	})[0]
 	ui.PrintAmend(commit.Date, amended, commit.Message)
 
-	if commit.Date == amended {
+	if config.SameDate(commit.Date, amended) {
 		return nil, nil
 	}
 	commit.Date = amended
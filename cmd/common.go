@@ -22,25 +22,25 @@ type DecentContext struct {
 }
 
 func commandPreRun(cmd *cobra.Command, args []string) error {
-	verbose, err := cmd.Flags().GetBool("verbose")
-	if err != nil {
-		return fmt.Errorf("error getting the verbose flag %w", err)
-	}
-	ui.SetVerbose(verbose)
-
-	err = security.Setup()
+	return scheduleFor(cmd, "", "")
+}
 
-	if err != nil {
-		//TODO: Handle somehow not to show the recurssion prevention
-		return err
+// Git gives the pre-push hook the name and the URL of the remote pushed to,
+// its schedule is used instead of the one of the upstream
+func pushPreRun(cmd *cobra.Command, args []string) error {
+	if len(args) < 2 {
+		return commandPreRun(cmd, args)
 	}
+	return scheduleFor(cmd, args[0], args[1])
+}
 
-	err = ui.Setup()
+func scheduleFor(cmd *cobra.Command, remote string, url string) error {
+	err := setupCommand(cmd)
 	if err != nil {
-		return fmt.Errorf("couldn't setup the ui %w", err)
+		return err
 	}
 
-	repo, schedule, err := repo.Setup()
+	repo, schedule, err := repo.SetupForRemote(remote, url)
 	if err != nil {
 		return err
 	}
@@ -56,6 +56,47 @@ func commandPreRun(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// Commands that inspect the configuration can't depend on it being valid,
+// they only get the repository
+func repoPreRun(cmd *cobra.Command, args []string) error {
+	err := setupCommand(cmd)
+	if err != nil {
+		return err
+	}
+
+	repo, err := repo.SetupRepo()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.WithValue(cmd.Context(), decentContextKey, &DecentContext{gitRepo: repo})
+	cmd.SetContext(ctx)
+
+	return nil
+}
+
+func setupCommand(cmd *cobra.Command) error {
+	verbose, err := cmd.Flags().GetBool("verbose")
+	if err != nil {
+		return fmt.Errorf("error getting the verbose flag %w", err)
+	}
+	ui.SetVerbose(verbose)
+
+	err = security.Setup()
+
+	if err != nil {
+		//TODO: Handle somehow not to show the recurssion prevention
+		return err
+	}
+
+	err = ui.Setup()
+	if err != nil {
+		return fmt.Errorf("couldn't setup the ui %w", err)
+	}
+
+	return nil
+}
+
 func commandPostRun() {
 	ui.TearDown()
 	repo.TearDown()
@@ -1,12 +1,24 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 
+	"github.com/afiestas/git-decent/cmd/repo"
+	"github.com/afiestas/git-decent/config"
+	"github.com/afiestas/git-decent/internal"
 	"github.com/afiestas/git-decent/ui"
+	u "github.com/afiestas/git-decent/utils"
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
 )
 
+const upcomingHolidaysDays = 90
+
 var configCmd = &cobra.Command{
 	Use:   "config",
 	Short: "It prints the current git decent config",
@@ -17,9 +29,479 @@ var configCmd = &cobra.Command{
 		}
 
 		schedule := decentContext.schedule
+		format, err := cmd.Flags().GetString("format")
+		if err != nil {
+			return err
+		}
+		if format != "" {
+			scopes, _ := decentContext.gitRepo.GetSectionScopes("decent")
+			return printScheduleAs(format, schedule, scopes)
+		}
+
+		showOrigin, err := cmd.Flags().GetBool("show-origin")
+		if err != nil {
+			return err
+		}
+		if showOrigin {
+			return printOrigins(decentContext.gitRepo, schedule)
+		}
+
 		ui.Title("\nSchedule")
 		ui.PrintSchedule(*schedule)
 
+		if schedule.Holidays != nil {
+			today := config.DateOf(schedule.In(time.Now()))
+			ui.Title(fmt.Sprintf("\nHolidays skipped in the next %d days", upcomingHolidaysDays))
+			ui.PrintHolidays(schedule.Holidays.Between(today, today.AddDays(upcomingHolidaysDays)))
+		}
+
+		return nil
+	},
+}
+
+func printOrigins(r *internal.GitRepo, schedule *config.Schedule) error {
+	policy, err := repo.PolicyOptions(r)
+	if err != nil {
+		return err
+	}
+	options, _ := r.GetSectionOptions("decent")
+	scopes, _ := r.GetSectionScopes("decent")
+
+	//Without personal configuration the team policy is the schedule
+	if len(options) == 0 {
+		options, policy = policy, nil
+		scopes = map[string]string{}
+		for key := range options {
+			scopes[key] = config.PolicyFile
+		}
+	}
+
+	ui.Title("\nSchedule")
+	ui.PrintOrigins(*schedule, options, scopes, policy)
+	return nil
+}
+
+func printScheduleAs(format string, schedule *config.Schedule, scopes map[string]string) error {
+	switch format {
+	case "json":
+		out, err := json.MarshalIndent(schedule.Export(scopes), "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+	case "yaml":
+		out, err := yaml.Marshal(schedule.Export(scopes))
+		if err != nil {
+			return err
+		}
+		fmt.Print(string(out))
+	case "ini":
+		fmt.Print(schedule.ExportINI(scopes))
+	default:
+		return fmt.Errorf("unknown format %s, use json, yaml or ini", format)
+	}
+	return nil
+}
+
+var lintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Checks the decent configuration",
+	Long: `Reports unknown keys (with a suggestion when it looks like a typo),
+invalid values and frames that overlap, are given twice or touch each other.
+Frames include their end minute, so 09:00/12:00 and 12:00/14:00 share 12:00.
+It exits with an error when any problem is found.`,
+	Args:              cobra.NoArgs,
+	PersistentPreRunE: repoPreRun,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		decentContext, ok := cmd.Context().Value(decentContextKey).(*DecentContext)
+		if !ok {
+			return fmt.Errorf("could not get context")
+		}
+
+		r := decentContext.gitRepo
+		policy, err := repo.PolicyOptions(r)
+		if err != nil {
+			return err
+		}
+		ops, err := r.GetSectionOptions("decent")
+		if err != nil && policy == nil {
+			return u.WrapE("git decent is not configured", err)
+		}
+
+		problems := []error{}
+		if len(ops) > 0 {
+			problems = append(problems, lintProblems(config.Lint(ops))...)
+		}
+		if policy != nil {
+			for _, problem := range lintProblems(config.Lint(policy)) {
+				problems = append(problems, fmt.Errorf("%s: %w", config.PolicyFile, problem))
+			}
+		}
+
+		//Frames the team policy doesn't allow are only reported when both are valid
+		if len(ops) > 0 && len(policy) > 0 {
+			s, err := config.NewScheduleFromMap(ops)
+			p, policyErr := config.NewScheduleFromMap(policy)
+			if err == nil && policyErr == nil {
+				s.Policy = &p
+				problems = append(problems, s.PolicyWidenings()...)
+			}
+		}
+
+		if len(problems) == 0 {
+			ui.Success("The configuration looks decent")
+			return nil
+		}
+		for _, problem := range problems {
+			ui.Error(problem.Error())
+		}
+		return fmt.Errorf("found %d problems in the configuration", len(problems))
+	},
+}
+
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Configures git decent with a preset or in the editor",
+	Long: `With --preset the named schedule is used as it is, days can still be
+set on top of it with git decent config set. Without it the default
+preset is opened in GIT_EDITOR to be adjusted.`,
+	Args:              cobra.NoArgs,
+	PersistentPreRunE: repoPreRun,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		decentContext, ok := cmd.Context().Value(decentContextKey).(*DecentContext)
+		if !ok {
+			return fmt.Errorf("could not get context")
+		}
+
+		scope, err := configScope(cmd)
+		if err != nil {
+			return err
+		}
+		preset, err := cmd.Flags().GetString("preset")
+		if err != nil {
+			return err
+		}
+
+		err = repo.InitConfiguration(decentContext.gitRepo, scope, preset)
+		if err != nil {
+			return err
+		}
+		ui.Success("Git decent is configured")
 		return nil
 	},
 }
+
+var inferCmd = &cobra.Command{
+	Use:   "infer",
+	Short: "Proposes a schedule from the commits of the repository",
+	Long: `The weekdays and hours holding most of the commits (90% by default)
+become the proposed schedule, read in the offset each commit was made with.
+It is printed as a [decent] section, with --apply its days replace the
+configured ones.`,
+	Args:              cobra.NoArgs,
+	PersistentPreRunE: repoPreRun,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		decentContext, ok := cmd.Context().Value(decentContextKey).(*DecentContext)
+		if !ok {
+			return fmt.Errorf("could not get context")
+		}
+		r := decentContext.gitRepo
+
+		author, err := cmd.Flags().GetString("author")
+		if err != nil {
+			return err
+		}
+		since, err := cmd.Flags().GetString("since")
+		if err != nil {
+			return err
+		}
+		coverage, err := cmd.Flags().GetFloat64("coverage")
+		if err != nil {
+			return err
+		}
+		apply, err := cmd.Flags().GetBool("apply")
+		if err != nil {
+			return err
+		}
+		scope, err := configScope(cmd)
+		if err != nil {
+			return err
+		}
+
+		log, err := r.LogBy(author, since)
+		if err != nil {
+			return u.WrapE("couldn't get the git log", err)
+		}
+		dates := make([]time.Time, len(log))
+		for k, commit := range log {
+			dates[k] = commit.Date
+		}
+
+		inference, err := config.InferSchedule(dates, coverage/100)
+		if err != nil {
+			return err
+		}
+
+		ui.Info("Commits within the proposal:", fmt.Sprintf("%d of %d", inference.Covered, inference.Commits))
+		fmt.Print(inference.Config.PlainText())
+		if !apply {
+			return nil
+		}
+
+		//Days not in the proposal shouldn't keep their frames
+		ops, _ := r.GetSectionOptionsIn(scope, "decent")
+		for key := range ops {
+			if !config.IsDayKey(key) {
+				continue
+			}
+			if err := r.UnsetConfigIn(scope, "decent."+key); err != nil {
+				return u.WrapE(fmt.Sprintf("couldn't unset %s", key), err)
+			}
+		}
+		err = repo.WriteConfig(r, scope, &inference.Config)
+		if err != nil {
+			return err
+		}
+
+		//Days and keys of the proposal set in other files still apply
+		file, err := r.ConfigFile(scope)
+		if err != nil {
+			return err
+		}
+		proposed := map[string]bool{}
+		for _, option := range inference.Config.Options() {
+			proposed[strings.ToLower(option.Key)] = true
+		}
+		all, _ := r.GetSectionOptions("decent")
+		keys := make([]string, 0, len(all))
+		for key := range all {
+			if config.IsDayKey(key) || proposed[key] {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			for _, other := range r.ConfigFiles("decent." + key) {
+				if other != file {
+					ui.Warning(fmt.Sprintf("%s is also set in %s and still applies, unset it there to use the proposal", key, other))
+				}
+			}
+		}
+		ui.Success("Schedule applied")
+		return nil
+	},
+}
+
+var setCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Sets a key of the decent configuration",
+	Long: `Validates the value and writes it to the repository configuration,
+or to the global one or a given file with --global and --file.
+Keys are the ones of the [decent] section, like Monday, weekdays, Off or
+rule.nineEighty.`,
+	Args:              cobra.ExactArgs(2),
+	PersistentPreRunE: repoPreRun,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		decentContext, ok := cmd.Context().Value(decentContextKey).(*DecentContext)
+		if !ok {
+			return fmt.Errorf("could not get context")
+		}
+
+		scope, err := configScope(cmd)
+		if err != nil {
+			return err
+		}
+
+		err = config.CheckOption(args[0], args[1])
+		if err != nil {
+			return u.WrapE("the value is not valid", err)
+		}
+
+		return decentContext.gitRepo.SetConfigIn(scope, "decent."+args[0], args[1])
+	},
+}
+
+var editCmd = &cobra.Command{
+	Use:   "edit",
+	Short: "Opens the decent configuration in the editor",
+	Long: `The [decent] sections are opened in GIT_EDITOR with their comments and
+written back once they are valid. Other sections of the file are untouched.`,
+	Args:              cobra.NoArgs,
+	PersistentPreRunE: repoPreRun,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		decentContext, ok := cmd.Context().Value(decentContextKey).(*DecentContext)
+		if !ok {
+			return fmt.Errorf("could not get context")
+		}
+
+		scope, err := configScope(cmd)
+		if err != nil {
+			return err
+		}
+
+		return repo.EditConfiguration(decentContext.gitRepo, scope)
+	},
+}
+
+func addScopeFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("local", false, "Use the repository config file, the default")
+	cmd.Flags().Bool("global", false, "Use the global config file")
+	cmd.Flags().String("file", "", "Use the given config file")
+	cmd.MarkFlagsMutuallyExclusive("local", "global", "file")
+}
+
+func configScope(cmd *cobra.Command) (internal.ConfigScope, error) {
+	global, err := cmd.Flags().GetBool("global")
+	if err != nil {
+		return nil, err
+	}
+	file, err := cmd.Flags().GetString("file")
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case global:
+		return internal.GlobalConfig, nil
+	case file != "":
+		return internal.FileConfig(file), nil
+	}
+	return internal.LocalConfig, nil
+}
+
+func lintProblems(err error) []error {
+	if err == nil {
+		return nil
+	}
+	if unwrap, ok := err.(interface{ Unwrap() []error }); ok {
+		return unwrap.Unwrap()
+	}
+	return []error{err}
+}
+
+var importIcsCmd = &cobra.Command{
+	Use:   "import-ics <file>",
+	Short: "Imports working hours and time off from an iCalendar file",
+	Long: `Recurring "working hours" events become the frames of their weekdays,
+busy or out of office events become days off or non decent blocks.
+With --link the calendar is not copied, instead it is read every time
+through decent.icsFile so changes in the calendar are picked up.`,
+	Args: cobra.ExactArgs(1),
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		decentContext, ok := cmd.Context().Value(decentContextKey).(*DecentContext)
+		if !ok {
+			return fmt.Errorf("could not get context")
+		}
+		r := decentContext.gitRepo
+		schedule := decentContext.schedule
+
+		ics, err := config.LoadIcsFile(args[0], schedule.Location)
+		if err != nil {
+			return u.WrapE("couldn't import the calendar", err)
+		}
+
+		ui.Title("Working hours")
+		for d := time.Sunday; d <= time.Saturday; d++ {
+			if frames, exists := ics.WorkingHours[d]; exists {
+				fmt.Printf("📅 %-10s %s\n", d.String()+":", frames)
+			}
+		}
+		ui.Title("Off")
+		for _, off := range ics.Off {
+			fmt.Printf("🏖️ %s\n", off)
+		}
+		ui.Title("Blocks")
+		fmt.Println("⛔", config.RawDateFrames(ics.Blocks))
+		if len(ics.Rules) > 0 {
+			ui.Title("Recurring")
+			for _, rule := range ics.Rules {
+				fmt.Printf("🔁 %s: %s\n", rule.Name, rule.Raw())
+			}
+		}
+		for _, summary := range ics.Skipped {
+			ui.Warning("Skipping", summary, "its recurrence is not supported")
+		}
+
+		link, err := cmd.Flags().GetBool("link")
+		if err != nil {
+			return err
+		}
+
+		if link {
+			path, err := filepath.Abs(args[0])
+			if err != nil {
+				return err
+			}
+			answer, err := ui.YesNoQuestion(fmt.Sprintf("\nDo you want to read %s from now on?", path))
+			if err != nil || !answer {
+				return err
+			}
+			return r.SetConfig("decent.IcsFile", path)
+		}
+
+		answer, err := ui.YesNoQuestion("\nDo you want to import it?")
+		if err != nil || !answer {
+			return err
+		}
+
+		for d, frames := range ics.RawWorkingHours() {
+			err = r.SetConfig("decent."+d.String(), frames)
+			if err != nil {
+				return err
+			}
+		}
+
+		off := config.RawOff(ics.Off)
+		if current, _ := r.GetConfig("decent.Off"); current != "" && off != "" {
+			off = current + ", " + off
+		}
+		if off != "" {
+			err = r.SetConfig("decent.Off", off)
+			if err != nil {
+				return err
+			}
+		}
+
+		blocks := config.RawDateFrames(ics.Blocks)
+		if current, _ := r.GetConfig("decent.Block"); current != "" && blocks != "" {
+			blocks = current + ", " + blocks
+		}
+		if blocks != "" {
+			err = r.SetConfig("decent.Block", blocks)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, rule := range ics.Rules {
+			err = r.SetConfig("decent.rule."+rule.Name, rule.Raw())
+			if err != nil {
+				return err
+			}
+		}
+
+		ui.Success("Calendar imported")
+		return nil
+	},
+}
+
+func init() {
+	initCmd.Flags().String("preset", "", "Use a named schedule: "+strings.Join(config.PresetNames(), ", "))
+	addScopeFlags(initCmd)
+	inferCmd.Flags().String("author", "", "Only the commits of this author, name or email")
+	inferCmd.Flags().String("since", "", "Only the commits since this date, like 6 months ago")
+	inferCmd.Flags().Float64("coverage", 90, "Percentage of the commits the schedule should hold")
+	inferCmd.Flags().Bool("apply", false, "Write the proposed schedule")
+	addScopeFlags(inferCmd)
+	configCmd.Flags().String("format", "", "Print the resolved schedule as json, yaml or ini")
+	configCmd.Flags().Bool("show-origin", false, "Print the layer (git config scope or team policy) each frame comes from")
+	addScopeFlags(setCmd)
+	addScopeFlags(editCmd)
+	importIcsCmd.Flags().Bool("link", false, "Read the calendar every time instead of copying it")
+}
@@ -41,7 +41,8 @@ This hook command adds a file semaphore to prevent the infinite loop from happen
 		r := decentContext.gitRepo
 		s := *decentContext.schedule
 
-		commit, err := amend(r, &s)
+		strategy, _ := cmd.Flags().GetString("strategy")
+		commit, err := amend(r, &s, strategy)
 		if err != nil {
 			return err
 		}
@@ -1,3 +1,3 @@
 #!/bin/bash
 
-git decent pre-push
+git decent pre-push "$@"
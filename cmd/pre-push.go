@@ -1,11 +1,13 @@
 package cmd
 
 import (
+	"bufio"
 	_ "embed"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/afiestas/git-decent/internal"
@@ -22,6 +24,9 @@ var prePushCmd = &cobra.Command{
 	Use:    "pre-push",
 	Short:  "Prevents pushign at undecent hours",
 	Hidden: true,
+	//The remote name and URL given by git
+	Args:              cobra.MaximumNArgs(2),
+	PersistentPreRunE: pushPreRun,
 
 	RunE: func(cmd *cobra.Command, args []string) error {
 		decentContext, ok := cmd.Context().Value(decentContextKey).(*DecentContext)
@@ -29,11 +34,16 @@ var prePushCmd = &cobra.Command{
 			return fmt.Errorf("could not get context")
 		}
 
+		if pushesOriginalDates(os.Stdin) {
+			ui.PrintTemplate(fmt.Sprintf(`{{W "The original dates in"}} {{Bold (W "%s")}} {{W "are private."}}`, internal.NotesRef))
+			ui.PrintTemplate((`Use {{S "git push --no-verify"}} to push them anyway`))
+			return errors.New("the original dates are not pushed")
+		}
+
 		r := decentContext.gitRepo
 		upstream := r.BranchUpstream(r.CurrentBranch())
 
-		aLog := fmt.Sprintf("%s...", upstream)
-		log, err := r.LogWithRevision(aLog)
+		log, err := r.UnpushedLog(upstream)
 		if err != nil {
 			return u.WrapE("Unable to get the log", err)
 		}
@@ -50,21 +60,40 @@ var prePushCmd = &cobra.Command{
 		}
 
 		s := decentContext.schedule
+		if s.LeavesCommits() {
+			ui.Success("Allowed to push, git decent is disabled for this remote and branch")
+			return nil
+		}
 		now := time.Now()
-		_, dMin := s.ClosestDecentMinute(now)
-		if dMin == 0 {
+		if s.Contains(now) {
 			ui.Success("Allowed to push, decent time")
 			return nil
 		}
 
 		current := now.Format("Mon 15:04")
 		ui.PrintTemplate(fmt.Sprintf(`{{Bold (W "%s")}} {{W "is not a decent time."}}`, current))
+		ui.PrintTemplate(fmt.Sprintf(`{{P "Next decent time is"}} {{Bold (P "%s")}}`, s.Next(now).Format("Mon 15:04")))
 		ui.PrintTemplate((`Use {{S "git push --no-verify"}} to skip the hook`))
 
 		return errors.New("it is not a decent time")
 	},
 }
 
+// Git gives the hook a line per pushed ref, the local ref first
+func pushesOriginalDates(stdin *os.File) bool {
+	if info, err := stdin.Stat(); err != nil || info.Mode()&os.ModeCharDevice != 0 {
+		return false
+	}
+
+	scanner := bufio.NewScanner(stdin)
+	for scanner.Scan() {
+		if localRef, _, _ := strings.Cut(scanner.Text(), " "); localRef == internal.NotesRef {
+			return true
+		}
+	}
+	return false
+}
+
 func containsCommitInFuture(log internal.GitLog) []internal.Commit {
 	now := time.Now()
 	commits := []internal.Commit{}
@@ -1,13 +1,15 @@
 package repo
 
 import (
+	"bytes"
 	_ "embed"
 	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
-	"time"
 
 	"github.com/afiestas/git-decent/config"
 	"github.com/afiestas/git-decent/internal"
@@ -15,21 +17,31 @@ import (
 	u "github.com/afiestas/git-decent/utils"
 )
 
-//go:embed config-template.ini
-var configTemplate string
+//go:embed config-help.ini
+var configHelp string
 
 func Setup() (*internal.GitRepo, *config.Schedule, error) {
-	repo, err := getRepo()
+	return SetupForRemote("", "")
+}
+
+// SetupForRemote reads the schedule of the given remote, like the one pushed
+// to, instead of the one the current branch tracks when it is not empty
+func SetupForRemote(remote string, url string) (*internal.GitRepo, *config.Schedule, error) {
+	repo, err := SetupRepo()
 	if err != nil {
-		return nil, nil, u.WrapE("couldn't setup the repository", err)
+		return nil, nil, err
 	}
 
-	_, err = repo.LogWithRevision("-1")
-	if err != nil {
-		return nil, nil, u.WrapE("couldn't get the git log", err)
+	target := Target(repo)
+	if remote != "" {
+		target.Remote, target.URL = remote, url
+		//Pushing to a URL instead of a named remote
+		if remote == url {
+			target.Remote = ""
+		}
 	}
 
-	schedule, err := getSchedule(repo)
+	schedule, err := getSchedule(repo, target)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -37,12 +49,35 @@ func Setup() (*internal.GitRepo, *config.Schedule, error) {
 	return repo, schedule, nil
 }
 
+// SetupRepo opens the repository without reading the schedule
+func SetupRepo() (*internal.GitRepo, error) {
+	repo, err := getRepo()
+	if err != nil {
+		return nil, u.WrapE("couldn't setup the repository", err)
+	}
+
+	_, err = repo.LogWithRevision("-1")
+	if err != nil {
+		return nil, u.WrapE("couldn't get the git log", err)
+	}
+
+	return repo, nil
+}
+
 func TearDown() {
 
 }
 
-func getSchedule(r *internal.GitRepo) (*config.Schedule, error) {
+func getSchedule(r *internal.GitRepo, target config.Target) (*config.Schedule, error) {
+	policy, err := getPolicy(r, target)
+	if err != nil {
+		return nil, err
+	}
+
 	ops, _ := r.GetSectionOptions("decent")
+	if len(ops) == 0 && policy != nil && !policy.Disabled {
+		return policy, nil
+	}
 
 	if len(ops) == 0 {
 		asnwer, err := ui.YesNoQuestion("Git decent is not configured, do you want to do it now?")
@@ -53,7 +88,7 @@ func getSchedule(r *internal.GitRepo) (*config.Schedule, error) {
 			return nil, nil
 		}
 
-		err = initConfiguration(r)
+		err = InitConfiguration(r, internal.LocalConfig, "")
 		if err != nil {
 			return nil, err
 		}
@@ -64,30 +99,105 @@ func getSchedule(r *internal.GitRepo) (*config.Schedule, error) {
 		}
 	}
 
-	s, err := config.NewScheduleFromMap(ops)
+	s, err := config.NewScheduleFor(ops, target)
 	if err != nil {
-		return nil, err
+		return nil, u.WrapE("the decent configuration is not valid, run git decent config lint for details", err)
 	}
+	s.Policy = policy
 	return &s, nil
 }
 
-func initConfiguration(repo *internal.GitRepo) error {
-	rawC, err := openGitEditor()
+// Target returns the current branch with the remote it tracks
+func Target(r *internal.GitRepo) config.Target {
+	branch := r.CurrentBranch()
+	remote := r.BranchRemote(branch)
+	url, _ := r.RemoteURL(remote)
+	if url == "" {
+		remote = ""
+	}
+	return config.Target{Remote: remote, URL: url, Branch: branch}
+}
+
+// PolicyOptions returns the [decent] options of the team policy, nil when
+// the repository doesn't have one
+func PolicyOptions(r *internal.GitRepo) (map[string]string, error) {
+	root, err := r.TopLevel()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	for x := time.Monday; x < time.Saturday; x++ {
-		if len(rawC.Days[x]) == 0 {
-			continue
-		}
-		err = repo.SetConfig("decent."+strings.Title(x.String()), rawC.Days[x])
+	path := filepath.Join(root, config.PolicyFile)
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	ops, _ := r.GetSectionOptionsIn(internal.FileConfig(path), "decent")
+	return ops, nil
+}
+
+func getPolicy(r *internal.GitRepo, target config.Target) (*config.Schedule, error) {
+	ops, err := PolicyOptions(r)
+	if err != nil || len(ops) == 0 {
+		return nil, err
+	}
+
+	policy, err := config.NewScheduleFor(ops, target)
+	if err != nil {
+		return nil, u.WrapE(fmt.Sprintf("the team policy %s is not valid, run git decent config lint for details", config.PolicyFile), err)
+	}
+	return &policy, nil
+}
+
+// InitConfiguration sets the given preset, without one the default preset is
+// opened in the editor to be adjusted
+func InitConfiguration(repo *internal.GitRepo, scope internal.ConfigScope, presetName string) error {
+	if presetName != "" {
+		preset, err := config.LookupPreset(presetName)
 		if err != nil {
 			return err
 		}
+		return repo.SetConfigIn(scope, "decent.Preset", preset.Name)
+	}
+
+	template, err := configTemplate(config.DefaultPreset)
+	if err != nil {
+		return err
+	}
+	_, rawC, err := openGitEditor(template)
+	if err != nil {
+		return err
+	}
+	if rawC == nil {
+		return errors.New("git decent is not configured")
+	}
+
+	return WriteConfig(repo, scope, rawC)
+}
+
+// The preset followed by the help, which lists the available presets
+func configTemplate(presetName string) (string, error) {
+	preset, err := config.LookupPreset(presetName)
+	if err != nil {
+		return "", err
+	}
+
+	presets := []string{}
+	for _, p := range config.Presets() {
+		presets = append(presets, fmt.Sprintf("#   %-22s %s", p.Name, p.Description))
+	}
+	keys := []string{}
+	for _, key := range config.Keys {
+		keys = append(keys, fmt.Sprintf("# %s = %s", key.Name, key.Example))
 	}
-	if len(rawC.Days[time.Sunday]) > 0 {
-		err = repo.SetConfig("decent."+strings.Title(time.Sunday.String()), rawC.Days[time.Sunday])
+
+	help := strings.Replace(configHelp, "# KEYS", strings.Join(keys, "\n        "), 1)
+	return preset.Text + strings.Replace(help, "# PRESETS", strings.Join(presets, "\n"), 1), nil
+}
+
+// WriteConfig sets every key of the configuration in the given scope, keys
+// already there and not part of the configuration are kept
+func WriteConfig(repo *internal.GitRepo, scope internal.ConfigScope, rawC *config.RawScheduleConfig) error {
+	for _, option := range rawC.Options() {
+		err := repo.SetConfigIn(scope, "decent."+option.Key, option.Value)
 		if err != nil {
 			return err
 		}
@@ -95,15 +205,58 @@ func initConfiguration(repo *internal.GitRepo) error {
 	return nil
 }
 
-func openGitEditor() (*config.RawScheduleConfig, error) {
+// EditConfiguration opens the [decent] sections of the scope in the editor,
+// comments included. When the scope has none the current configuration is used.
+func EditConfiguration(repo *internal.GitRepo, scope internal.ConfigScope) error {
+	path, err := repo.ConfigFile(scope)
+	if err != nil {
+		return u.WrapE("couldn't find the git config file", err)
+	}
+
+	mode := os.FileMode(0644)
+	content, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode()
+	}
+
+	sections := config.DecentSections(string(content))
+	if strings.TrimSpace(sections) == "" {
+		sections, err = configTemplate(config.DefaultPreset)
+		if err != nil {
+			return err
+		}
+		if ops, _ := repo.GetSectionOptions("decent"); len(ops) > 0 {
+			rawC, _ := config.GetGitRawConfig(&ops)
+			sections = rawC.PlainText()
+		}
+	}
+
+	edited, rawC, err := openGitEditor(sections)
+	if err != nil || rawC == nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(config.ReplaceDecentSections(string(content), edited)), mode)
+}
+
+// openGitEditor returns the edited text and its configuration, which is nil
+// when the user gives up on fixing it
+func openGitEditor(content string) (string, *config.RawScheduleConfig, error) {
 	gitcfg := exec.Command("git", "var", "GIT_EDITOR")
 	editorName, err := gitcfg.Output()
 	if err != nil {
-		return nil, fmt.Errorf("openGitEditor coudn't fetch the GIT_EDITOR var")
+		return "", nil, fmt.Errorf("openGitEditor coudn't fetch the GIT_EDITOR var")
 	}
 
 	if len(editorName) == 0 {
-		return nil, fmt.Errorf("openGitEditor empty editor configured")
+		return "", nil, fmt.Errorf("openGitEditor empty editor configured")
 	}
 
 	var args []string
@@ -116,7 +269,7 @@ func openGitEditor() (*config.RawScheduleConfig, error) {
 
 	f, err := os.CreateTemp(os.TempDir(), "schedule-tempalte")
 	if err != nil {
-		return nil, fmt.Errorf("openGitEditor can't create tmp file %w", err)
+		return "", nil, fmt.Errorf("openGitEditor can't create tmp file %w", err)
 	}
 
 	defer func() {
@@ -124,8 +277,7 @@ func openGitEditor() (*config.RawScheduleConfig, error) {
 		os.RemoveAll(f.Name())
 	}()
 
-	f.WriteString(configTemplate)
-	f.Seek(0, 0)
+	f.WriteString(content)
 
 	args = append(args, f.Name())
 	for {
@@ -136,24 +288,30 @@ func openGitEditor() (*config.RawScheduleConfig, error) {
 
 		err = editor.Run()
 		if err != nil {
-			return nil, err
+			return "", nil, err
 		}
 
-		rawC, err := config.NewScheduleFromPlainText(f)
+		//Editors might replace the file instead of writing to it
+		edited, err := os.ReadFile(f.Name())
+		if err != nil {
+			return "", nil, err
+		}
+
+		rawC, err := config.NewScheduleFromPlainText(bytes.NewReader(edited))
 		if err == nil {
-			_, err := config.NewScheduleFromRaw(rawC)
+			_, err = config.NewScheduleFromRaw(rawC)
 			if err == nil {
-				return rawC, nil
+				return string(edited), rawC, nil
 			}
 		}
 
 		fmt.Println("the configuration coudln't be parsed", err)
 		answer, err := ui.YesNoQuestion("Do you want to edit it again?")
 		if err != nil {
-			return nil, err
+			return "", nil, err
 		}
 		if !answer {
-			return nil, nil
+			return "", nil, nil
 		}
 	}
 }
@@ -0,0 +1,100 @@
+/* SPDX-License-Identifier: MIT */
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/afiestas/git-decent/config"
+	"github.com/afiestas/git-decent/internal"
+	"github.com/afiestas/git-decent/ui"
+	u "github.com/afiestas/git-decent/utils"
+	"github.com/spf13/cobra"
+)
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore [<range>]",
+	Short: "Puts back the dates commits were made at",
+	Long: `Rewrites the amended commits with the dates they were made at, kept in
+refs/notes/decent when they were amended. The range defaults to the unpushed
+commits and has to end at HEAD, like HEAD~3.. or HEAD^.. for the last one.`,
+	Args:              cobra.MaximumNArgs(1),
+	PersistentPreRunE: repoPreRun,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		decentContext, ok := cmd.Context().Value(decentContextKey).(*DecentContext)
+		if !ok {
+			return fmt.Errorf("could not get context")
+		}
+		r := decentContext.gitRepo
+
+		var log internal.GitLog
+		var err error
+		revisionRange := ""
+		if len(args) > 0 {
+			revisionRange = args[0]
+			log, err = r.LogWithRevision(revisionRange)
+		} else {
+			upstream := r.BranchUpstream(r.CurrentBranch())
+			if upstream == "" {
+				return errors.New("the branch has no upstream, give a range like HEAD~3..")
+			}
+			revisionRange = upstream + "..."
+			log, err = r.UnpushedLog(upstream)
+		}
+		if err != nil {
+			return u.WrapE("couldn't get the log", err)
+		}
+		if len(log) == 0 {
+			ui.Success("No commits to restore")
+			return nil
+		}
+
+		head, err := r.LogWithRevision("-1")
+		if err != nil {
+			return u.WrapE("couldn't get the log", err)
+		}
+		if len(head) == 0 || log[len(log)-1].Hash != head[0].Hash {
+			return fmt.Errorf("the range %s doesn't end at HEAD", revisionRange)
+		}
+
+		restored, err := r.WithOriginalDates(log)
+		if err != nil {
+			return err
+		}
+
+		//Commits before the first one to restore are left as they are
+		first := len(restored)
+		restoredCount := 0
+		for k, commit := range restored {
+			ui.PrintAmend(log[k].Date, commit.Date, commit.Message)
+			if !config.SameDate(commit.Date, log[k].Date) {
+				first = min(first, k)
+				restoredCount++
+			}
+		}
+		ui.Info("Restored commits:", fmt.Sprintf("%d", restoredCount))
+		if restoredCount == 0 {
+			return nil
+		}
+		restored = restored[first:]
+
+		answer, err := ui.YesNoQuestion("Do you want to restore the dates?")
+		if err != nil {
+			return err
+		}
+		if !answer {
+			return nil
+		}
+
+		if len(restored) == 1 {
+			err = r.AmendDate(restored[0])
+		} else {
+			err = r.AmendDates(restored)
+		}
+		if err != nil {
+			return u.WrapE("error while restoring the dates", err)
+		}
+		return nil
+	},
+}
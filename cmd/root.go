@@ -4,8 +4,9 @@ package cmd
 import (
 	"fmt"
 	"os"
-	"time"
+	"strings"
 
+	"github.com/afiestas/git-decent/config"
 	"github.com/afiestas/git-decent/internal"
 	"github.com/afiestas/git-decent/ui"
 	"github.com/spf13/cobra"
@@ -33,13 +34,15 @@ maintain appearances while working during unconventional hours...`,
 		ui.Title("Schedule:")
 		ui.PrintSchedule(s)
 		fmt.Println()
+		if s.LeavesCommits() {
+			return
+		}
 
 		ui.Title("Current status")
 		upstream := r.BranchUpstream(r.CurrentBranch())
 		ui.Info("Upstream branch", upstream)
 
-		aLog := fmt.Sprintf("%s...", upstream)
-		log, err := r.LogWithRevision(aLog)
+		log, err := r.UnpushedLog(upstream)
 		if err != nil {
 			ui.PrintError(err)
 			return
@@ -50,25 +53,29 @@ maintain appearances while working during unconventional hours...`,
 			return
 		}
 
-		amendedCount := 0
-		var lastRealDate *time.Time = nil
-		var lastDate *time.Time = nil
-		for k, commit := range log {
-			if commit.Prev != nil {
-				commit.Prev = log[k-1]
-				lastDate = &commit.Prev.Date
-			}
-			commitDate := commit.Date
-			amended := internal.Amend(commitDate, lastDate, lastRealDate, 0, s)
-			lastRealDate = &commit.Date
+		strategy, _ := cmd.Flags().GetString("strategy")
+		amender, err := internal.NewAmender(strategy, s)
+		if err != nil {
+			ui.PrintError(err)
+			return
+		}
 
-			ui.PrintAmend(commitDate, amended, commit.Message)
-			if amended != commitDate {
+		//Commits amended before keep the real time between them and none
+		//goes before the pushed ones
+		batch, err := r.BatchOf(log)
+		if err != nil {
+			ui.PrintError(err)
+			return
+		}
+
+		amendedCount := 0
+		for k, amended := range amender.Amend(batch) {
+			commit := log[k]
+			ui.PrintAmend(commit.Date, amended, commit.Message)
+			if !config.SameDate(amended, commit.Date) {
 				amendedCount += 1
 			}
-
 			commit.Date = amended
-			log[k] = commit
 		}
 
 		ui.Info("Amended commits:", fmt.Sprintf("%d", amendedCount))
@@ -100,7 +107,14 @@ func Execute() {
 	prePushCmd.AddCommand(installPrePush)
 	rootCmd.AddCommand(prePushCmd)
 	rootCmd.AddCommand(amendCmd)
+	rootCmd.AddCommand(restoreCmd)
 	rootCmd.AddCommand(installCdm)
+	configCmd.AddCommand(importIcsCmd)
+	configCmd.AddCommand(lintCmd)
+	configCmd.AddCommand(initCmd)
+	configCmd.AddCommand(inferCmd)
+	configCmd.AddCommand(setCmd)
+	configCmd.AddCommand(editCmd)
 	rootCmd.AddCommand(configCmd)
 	err := rootCmd.Execute()
 	commandPostRun()
@@ -114,4 +128,5 @@ func Execute() {
 
 func init() {
 	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "Verbose output")
+	rootCmd.PersistentFlags().String("strategy", "", "How commits are amended: "+strings.Join(config.Strategies, ", "))
 }
@@ -0,0 +1,140 @@
+/* SPDX-License-Identifier: MIT */
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Most commits amended into an hour or into a decent frame, the ones over it
+// spill to the following hours, frames and days
+type Capacity struct {
+	Commits  int
+	PerFrame bool
+}
+
+// Like 6/hour or 20/frame
+func parseCapacity(value string) (*Capacity, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+
+	rawCommits, per, found := strings.Cut(value, "/")
+	commits, err := strconv.Atoi(strings.TrimSpace(rawCommits))
+	if !found || err != nil || commits < 1 {
+		return nil, fmt.Errorf("capacity: expected commits per hour or frame like 6/hour, given %s", value)
+	}
+
+	switch strings.ToLower(strings.TrimSpace(per)) {
+	case "hour":
+		return &Capacity{Commits: commits}, nil
+	case "frame":
+		return &Capacity{Commits: commits, PerFrame: true}, nil
+	}
+	return nil, fmt.Errorf("capacity: expected hour or frame after the commits, given %s", per)
+}
+
+func (c Capacity) Raw() string {
+	if c.PerFrame {
+		return fmt.Sprintf("%d/frame", c.Commits)
+	}
+	return fmt.Sprintf("%d/hour", c.Commits)
+}
+
+func (c Capacity) String() string {
+	if c.PerFrame {
+		return fmt.Sprintf("%d commits per frame", c.Commits)
+	}
+	return fmt.Sprintf("%d commits per hour", c.Commits)
+}
+
+// Minutes of MinGap and Threshold
+func parseMinutes(key string, value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+
+	minutes, err := strconv.Atoi(value)
+	if err != nil || minutes < 0 {
+		return 0, fmt.Errorf("%s: expected minutes, given %s", key, value)
+	}
+	return time.Duration(minutes) * time.Minute, nil
+}
+
+// The minutes of MinGap and Threshold as written in the configuration
+func rawMinutes(d time.Duration) string {
+	if d == 0 {
+		return ""
+	}
+	return strconv.Itoa(int(d.Minutes()))
+}
+
+// Minutes added to the commits moved to a decent time, uniformly distributed
+// or normally around the middle of the range
+type Noise struct {
+	Min    int
+	Max    int
+	Normal bool
+}
+
+// Like 1-10, 1-10 uniform or 0-20 normal
+func parseNoise(value string) (*Noise, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+
+	fields := strings.Fields(value)
+	rawLow, rawHigh, found := strings.Cut(fields[0], "-")
+	low, lowErr := strconv.Atoi(rawLow)
+	high, highErr := strconv.Atoi(rawHigh)
+	if !found || lowErr != nil || highErr != nil || low < 0 || high < low || len(fields) > 2 {
+		return nil, fmt.Errorf("noise: expected a range of minutes like 1-10 and optionally uniform or normal, given %s", value)
+	}
+
+	noise := &Noise{Min: low, Max: high}
+	if len(fields) == 2 {
+		switch strings.ToLower(fields[1]) {
+		case "uniform":
+		case "normal":
+			noise.Normal = true
+		default:
+			return nil, fmt.Errorf("noise: unknown distribution %s, expected uniform or normal", fields[1])
+		}
+	}
+	return noise, nil
+}
+
+func (n Noise) Raw() string {
+	if n.Normal {
+		return fmt.Sprintf("%d-%d normal", n.Min, n.Max)
+	}
+	return fmt.Sprintf("%d-%d", n.Min, n.Max)
+}
+
+func (n Noise) String() string {
+	distribution := "uniform"
+	if n.Normal {
+		distribution = "normal"
+	}
+	return fmt.Sprintf("%d to %d minutes, %s", n.Min, n.Max, distribution)
+}
+
+// Most seconds set on the commits moved to a decent time, up to 59 so they
+// stay in their minute
+func parseJitter(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds < 0 || seconds > 59 {
+		return 0, fmt.Errorf("jitter: expected seconds between 0 and 59, given %s", value)
+	}
+	return time.Duration(seconds) * time.Second, nil
+}
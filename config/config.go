@@ -2,36 +2,203 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"slices"
+	"sort"
+	"strings"
 	"time"
 )
 
 const section = "decent"
 
+// Strategies to amend commits
+const (
+	NextFrame     = "next-frame"
+	PreviousFrame = "previous-frame"
+	Spread        = "spread"
+	PreserveGaps  = "preserve-gaps"
+)
+
+// Strategies sorted as listed in the help, the first one is the default
+var Strategies = []string{NextFrame, PreviousFrame, Spread, PreserveGaps}
+
 type RawScheduleConfig struct {
-	Days map[time.Weekday]string
+	// Named schedule whose days are used when not configured
+	Preset   string
+	Days     map[time.Weekday]string
+	Timezone string
+	Off      string
+	Override string
+	Holidays string
+	Block    string
+	IcsFile  string
+	// Rules by name, from the [decent "rule"] subsection
+	Rules map[string]string
+	// false, no, off or 0 disable git decent
+	Enabled string
+	// How commits are amended, one of Strategies
+	Strategy string
+	// Commits per hour or frame like 6/hour and minutes between them
+	Capacity string
+	MinGap   string
+	// Minutes under which real intervals are kept, noise in minutes like
+	// 1-10 normal and most random seconds
+	Threshold string
+	Noise     string
+	Jitter    string
+	// Options by subsection, like remote.origin or branch.release/*
+	Contexts map[string]map[string]string
+}
+
+// A [decent] key besides the days and the rules, with the field keeping its
+// value, how the value is checked and an example for the help
+type Key struct {
+	Name    string
+	Example string
+	field   func(*RawScheduleConfig) *string
+	check   func(value string) error
+}
+
+// Keys in the order they are written, Preset first since the days win over it
+var Keys = []Key{
+	{"Preset", "eu-9to6", func(c *RawScheduleConfig) *string { return &c.Preset }, nil},
+	{"Timezone", "America/New_York", func(c *RawScheduleConfig) *string { return &c.Timezone }, nil},
+	{"Off", "2026-12-24..2026-12-26, 2027-01-01", func(c *RawScheduleConfig) *string { return &c.Off }, nil},
+	{"Override", "2026-11-02 10:00/12:00", func(c *RawScheduleConfig) *string { return &c.Override }, nil},
+	{"Holidays", "US", func(c *RawScheduleConfig) *string { return &c.Holidays }, nil},
+	{"Block", "2026-11-02 13:00/15:00", func(c *RawScheduleConfig) *string { return &c.Block }, nil},
+	{"IcsFile", "~/calendar.ics", func(c *RawScheduleConfig) *string { return &c.IcsFile }, nil},
+	{"Enabled", "false", func(c *RawScheduleConfig) *string { return &c.Enabled }, func(value string) error {
+		if _, err := parseBool(value); err != nil {
+			return fmt.Errorf("enabled: %w", err)
+		}
+		return nil
+	}},
+	{"Strategy", "spread", func(c *RawScheduleConfig) *string { return &c.Strategy }, func(value string) error {
+		if !slices.Contains(Strategies, strings.ToLower(value)) {
+			return fmt.Errorf("strategy: unknown strategy %s, expected one of %s", value, strings.Join(Strategies, ", "))
+		}
+		return nil
+	}},
+	{"Capacity", "6/hour", func(c *RawScheduleConfig) *string { return &c.Capacity }, func(value string) error {
+		_, err := parseCapacity(value)
+		return err
+	}},
+	{"MinGap", "10", func(c *RawScheduleConfig) *string { return &c.MinGap }, func(value string) error {
+		_, err := parseMinutes("mingap", value)
+		return err
+	}},
+	{"Threshold", "120", func(c *RawScheduleConfig) *string { return &c.Threshold }, func(value string) error {
+		_, err := parseMinutes("threshold", value)
+		return err
+	}},
+	{"Noise", "2-15 normal", func(c *RawScheduleConfig) *string { return &c.Noise }, func(value string) error {
+		_, err := parseNoise(value)
+		return err
+	}},
+	{"Jitter", "59", func(c *RawScheduleConfig) *string { return &c.Jitter }, func(value string) error {
+		_, err := parseJitter(value)
+		return err
+	}},
+}
+
+// The key by its name in lower case, like git config gives them
+func lookupKey(name string) (Key, bool) {
+	for _, key := range Keys {
+		if strings.ToLower(key.Name) == name {
+			return key, true
+		}
+	}
+	return Key{}, false
+}
+
+var dayNames = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"sun":       time.Sunday,
+	"monday":    time.Monday,
+	"mon":       time.Monday,
+	"tuesday":   time.Tuesday,
+	"tue":       time.Tuesday,
+	"tues":      time.Tuesday,
+	"wednesday": time.Wednesday,
+	"wed":       time.Wednesday,
+	"thursday":  time.Thursday,
+	"thu":       time.Thursday,
+	"thur":      time.Thursday,
+	"thurs":     time.Thursday,
+	"friday":    time.Friday,
+	"fri":       time.Friday,
+	"saturday":  time.Saturday,
+	"sat":       time.Saturday,
+}
+
+// daysOf returns the weekdays of a key like monday, mon, weekdays, weekend
+// or mon-fri, ranges can wrap around the week like fri-mon
+func daysOf(key string) []time.Weekday {
+	switch key {
+	case "weekdays":
+		return []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+	case "weekend", "weekends":
+		return []time.Weekday{time.Saturday, time.Sunday}
+	}
+
+	if day, exists := dayNames[key]; exists {
+		return []time.Weekday{day}
+	}
+
+	rawFrom, rawTo, found := strings.Cut(key, "-")
+	from, fromExists := dayNames[rawFrom]
+	to, toExists := dayNames[rawTo]
+	if !found || !fromExists || !toExists {
+		return nil
+	}
+
+	days := []time.Weekday{from}
+	for day := from; day != to; {
+		day = (day + 1) % 7
+		days = append(days, day)
+	}
+	return days
+}
+
+// IsDayKey tells if the key sets the frames of one or more weekdays
+func IsDayKey(key string) bool {
+	return daysOf(strings.ToLower(key)) != nil
 }
 
+// SetValue sets a [decent] key, frames of weekdays are validated and stored
+// even when invalid so the schedule reports them as well
 func (config *RawScheduleConfig) SetValue(day string, value string) error {
-	switch day {
-	case "monday":
-		config.Days[time.Monday] = value
-	case "tuesday":
-		config.Days[time.Tuesday] = value
-	case "wednesday":
-		config.Days[time.Wednesday] = value
-	case "thursday":
-		config.Days[time.Thursday] = value
-	case "friday":
-		config.Days[time.Friday] = value
-	case "saturday":
-		config.Days[time.Saturday] = value
-	case "sunday":
-		config.Days[time.Sunday] = value
-	default:
-		return fmt.Errorf("invalid day configured, got %s with value %s", day, value)
-	}
-	return nil
+	if days := daysOf(day); days != nil {
+		for _, d := range days {
+			config.Days[d] = value
+		}
+		if err := validateFrames(value); err != nil {
+			return fmt.Errorf("%s: %w", day, err)
+		}
+		return nil
+	}
+
+	if key, found := lookupKey(day); found {
+		*key.field(config) = value
+		if key.check != nil {
+			return key.check(value)
+		}
+		return nil
+	}
+
+	if name, found := strings.CutPrefix(day, "rule."); found {
+		if config.Rules == nil {
+			config.Rules = map[string]string{}
+		}
+		config.Rules[name] = value
+		return nil
+	}
+	if context, key, found := splitContextKey(day); found {
+		return config.setContextValue(context, key, value)
+	}
+	return fmt.Errorf("invalid day configured, got %s with value %s", day, value)
 }
 
 func GetGitRawConfig(options *map[string]string) (RawScheduleConfig, error) {
@@ -39,9 +206,27 @@ func GetGitRawConfig(options *map[string]string) (RawScheduleConfig, error) {
 		Days: make(map[time.Weekday]string),
 	}
 
-	for day, value := range *options {
-		rawC.SetValue(day, value)
+	errs := []error{}
+	for _, day := range sortKeysByDays(*options) {
+		if err := rawC.SetValue(day, (*options)[day]); err != nil {
+			errs = append(errs, err)
+		}
 	}
 
-	return rawC, nil
+	return rawC, errors.Join(errs...)
+}
+
+// Keys covering more days go first so single days win over ranges
+func sortKeysByDays(options map[string]string) []string {
+	keys := make([]string, 0, len(options))
+	for key := range options {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if len(daysOf(keys[i])) != len(daysOf(keys[j])) {
+			return len(daysOf(keys[i])) > len(daysOf(keys[j]))
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
 }
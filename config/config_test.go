@@ -27,3 +27,33 @@ func TestGetGitRawConfig(t *testing.T) {
 
 	assert.Equal(t, rawC, expectedRawC)
 }
+
+func TestDaysOf(t *testing.T) {
+	assert.Equal(t, []time.Weekday{time.Monday}, daysOf("mon"))
+	assert.Equal(t, []time.Weekday{time.Thursday}, daysOf("thurs"))
+	assert.Equal(t, []time.Weekday{time.Saturday, time.Sunday}, daysOf("weekend"))
+	assert.Len(t, daysOf("weekdays"), 5)
+	assert.Equal(t, []time.Weekday{time.Tuesday, time.Wednesday, time.Thursday}, daysOf("tue-thu"))
+	assert.Equal(t, []time.Weekday{time.Friday, time.Saturday, time.Sunday, time.Monday}, daysOf("fri-mon"))
+	assert.Nil(t, daysOf("timezone"))
+	assert.Nil(t, daysOf("mon-someday"))
+}
+
+func TestSetValueDayRanges(t *testing.T) {
+	options := map[string]string{
+		"friday":   "09:00/13:00",
+		"mon-fri":  "9-17",
+		"weekends": "10-12",
+	}
+	rawC, err := GetGitRawConfig(&options)
+	assert.NoError(t, err)
+
+	//Single days win over ranges
+	assert.Equal(t, "09:00/13:00", rawC.Days[time.Friday])
+	assert.Equal(t, "9-17", rawC.Days[time.Monday])
+	assert.Equal(t, "10-12", rawC.Days[time.Sunday])
+
+	err = rawC.SetValue("tue", "9-17, 9:60-10")
+	assert.ErrorContains(t, err, `tue: frame "9:60-10": minute out of range in "9:60"`)
+	assert.Equal(t, "9-17, 9:60-10", rawC.Days[time.Tuesday])
+}
@@ -0,0 +1,144 @@
+/* SPDX-License-Identifier: MIT */
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Subsections whose options only apply to a remote, a remote URL or a
+// branch: [decent "remote.origin"], [decent "url.*github.com/me/*"] and
+// [decent "branch.release/*"]. Names of URLs and branches are globs.
+var contextKinds = []string{"url", "remote", "branch"}
+
+// Where the schedule is used, empty values don't match any subsection
+type Target struct {
+	Remote string
+	URL    string
+	Branch string
+}
+
+// The subsection and the key within it, like remote.origin and enabled or
+// branch.main and rule.standup
+func splitContextKey(key string) (string, string, bool) {
+	kind, rest, found := strings.Cut(key, ".")
+	if !found || !isContextKind(kind) {
+		return "", "", false
+	}
+	k := strings.LastIndex(rest, ".")
+	if k == -1 {
+		return "", "", false
+	}
+	name, key := rest[:k], rest[k+1:]
+	if rule, found := strings.CutSuffix(name, ".rule"); found {
+		name, key = rule, "rule."+key
+	}
+	return kind + "." + name, key, true
+}
+
+func isContextKind(kind string) bool {
+	for _, known := range contextKinds {
+		if kind == known {
+			return true
+		}
+	}
+	return false
+}
+
+func (config *RawScheduleConfig) setContextValue(context string, key string, value string) error {
+	//Validated like the general keys
+	scratch := RawScheduleConfig{Days: map[time.Weekday]string{}}
+	if err := scratch.SetValue(key, value); err != nil {
+		return fmt.Errorf("%s: %w", context, err)
+	}
+
+	if config.Contexts == nil {
+		config.Contexts = map[string]map[string]string{}
+	}
+	if config.Contexts[context] == nil {
+		config.Contexts[context] = map[string]string{}
+	}
+	config.Contexts[context][key] = value
+	return nil
+}
+
+// Resolve returns the configuration for the target, the options of the
+// matching subsections win over the general ones. Branches win over remotes
+// and remotes over URLs, between globs of the same kind the longest wins.
+func (config *RawScheduleConfig) Resolve(target Target) RawScheduleConfig {
+	resolved := *config
+	resolved.Days = map[time.Weekday]string{}
+	for d, v := range config.Days {
+		resolved.Days[d] = v
+	}
+	resolved.Rules = map[string]string{}
+	for name, v := range config.Rules {
+		resolved.Rules[name] = v
+	}
+
+	for _, context := range matchingContexts(config.Contexts, target) {
+		for _, key := range sortKeysByDays(config.Contexts[context]) {
+			resolved.SetValue(key, config.Contexts[context][key])
+		}
+	}
+	return resolved
+}
+
+func matchingContexts(contexts map[string]map[string]string, target Target) []string {
+	matching := []string{}
+	for context := range contexts {
+		kind, name, _ := strings.Cut(context, ".")
+		value := map[string]string{"url": target.URL, "remote": target.Remote, "branch": target.Branch}[kind]
+		if value != "" && matchGlob(name, value) {
+			matching = append(matching, context)
+		}
+	}
+
+	rank := func(context string) int {
+		kind, _, _ := strings.Cut(context, ".")
+		for k, known := range contextKinds {
+			if kind == known {
+				return k
+			}
+		}
+		return -1
+	}
+	sort.Slice(matching, func(i, j int) bool {
+		if rank(matching[i]) != rank(matching[j]) {
+			return rank(matching[i]) < rank(matching[j])
+		}
+		if len(matching[i]) != len(matching[j]) {
+			return len(matching[i]) < len(matching[j])
+		}
+		return matching[i] < matching[j]
+	})
+	return matching
+}
+
+// * matches any text, slashes included, and ? a single character
+func matchGlob(pattern string, value string) bool {
+	expr := regexp.QuoteMeta(pattern)
+	expr = strings.ReplaceAll(expr, `\*`, ".*")
+	expr = strings.ReplaceAll(expr, `\?`, ".")
+	matched, err := regexp.MatchString("^"+expr+"$", value)
+	return err == nil && matched
+}
+
+// Like git config: true, yes, on and 1 or false, no, off and 0
+func parseBool(value string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "true", "yes", "on":
+		return true, nil
+	case "false", "no", "off":
+		return false, nil
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return false, fmt.Errorf("expected true or false but given %q", value)
+	}
+	return n != 0, nil
+}
@@ -0,0 +1,142 @@
+/* SPDX-License-Identifier: MIT */
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitContextKey(t *testing.T) {
+	context, key, found := splitContextKey("remote.origin.enabled")
+	assert.True(t, found)
+	assert.Equal(t, "remote.origin", context)
+	assert.Equal(t, "enabled", key)
+
+	context, key, _ = splitContextKey("url.*github.com/me/*.monday")
+	assert.Equal(t, "url.*github.com/me/*", context)
+	assert.Equal(t, "monday", key)
+
+	context, key, _ = splitContextKey("branch.release/*.rule.standup")
+	assert.Equal(t, "branch.release/*", context)
+	assert.Equal(t, "rule.standup", key)
+
+	_, _, found = splitContextKey("rule.standup")
+	assert.False(t, found)
+	_, _, found = splitContextKey("remote.enabled")
+	assert.False(t, found)
+}
+
+func TestMatchGlob(t *testing.T) {
+	assert.True(t, matchGlob("release/*", "release/1.0"))
+	assert.False(t, matchGlob("*github.com/me/*", "git@github.com:work/app.git"))
+	assert.True(t, matchGlob("*github.com/me/*", "https://github.com/me/fork.git"))
+	assert.True(t, matchGlob("v?", "v1"))
+	assert.False(t, matchGlob("v?", "v10"))
+	assert.False(t, matchGlob("main", "main2"))
+	assert.True(t, matchGlob("a.b", "a.b"))
+	assert.False(t, matchGlob("a.b", "axb"))
+}
+
+func TestResolve(t *testing.T) {
+	options := map[string]string{
+		"weekdays":                      "9-17",
+		"remote.origin.monday":          "10-18",
+		"remote.origin.tuesday":         "10-18",
+		"url.*github.com/me/*.enabled":  "false",
+		"branch.release/*.monday":       "8-12",
+		"branch.release/1.*.monday":     "7-11",
+		"branch.release/*.rule.standup": "FREQ=WEEKLY;BYDAY=MO block 09:00/09:30",
+	}
+	rawC, err := GetGitRawConfig(&options)
+	require.NoError(t, err)
+
+	resolved := rawC.Resolve(Target{})
+	assert.Equal(t, "9-17", resolved.Days[time.Monday])
+	assert.Empty(t, resolved.Enabled)
+
+	resolved = rawC.Resolve(Target{Remote: "origin", URL: "git@work.com:app.git", Branch: "main"})
+	assert.Equal(t, "10-18", resolved.Days[time.Monday])
+	assert.Equal(t, "10-18", resolved.Days[time.Tuesday])
+	assert.Equal(t, "9-17", resolved.Days[time.Wednesday])
+
+	//Branches win over remotes and the longest glob wins
+	resolved = rawC.Resolve(Target{Remote: "origin", Branch: "release/1.2"})
+	assert.Equal(t, "7-11", resolved.Days[time.Monday])
+	assert.Equal(t, "10-18", resolved.Days[time.Tuesday])
+	assert.Contains(t, resolved.Rules, "standup")
+	assert.Empty(t, rawC.Rules, "resolving doesn't change the configuration")
+
+	resolved = rawC.Resolve(Target{Remote: "origin", URL: "https://github.com/me/fork.git"})
+	assert.Equal(t, "false", resolved.Enabled)
+}
+
+func TestDisabledSchedule(t *testing.T) {
+	s, err := NewScheduleFor(map[string]string{
+		"weekdays":              "9-17",
+		"remote.origin.enabled": "no",
+	}, Target{Remote: "origin"})
+	require.NoError(t, err)
+	assert.True(t, s.Disabled)
+
+	night := time.Date(2026, time.November, 7, 3, 0, 0, 0, time.UTC)
+	assert.True(t, s.Contains(night))
+	assertTime(t, night, s.Next(night))
+
+	s, err = NewScheduleFor(map[string]string{
+		"weekdays":              "9-17",
+		"remote.origin.enabled": "no",
+	}, Target{Remote: "upstream"})
+	require.NoError(t, err)
+	assert.False(t, s.Disabled)
+	assert.False(t, s.Contains(night))
+
+	//The team policy still applies
+	s, err = NewScheduleFor(map[string]string{"enabled": "false"}, Target{})
+	require.NoError(t, err)
+	assert.True(t, s.LeavesCommits())
+	policy, err := NewScheduleFromMap(map[string]string{"mon-fri": "8-18"})
+	require.NoError(t, err)
+	s.Policy = &policy
+	assert.False(t, s.LeavesCommits())
+	assert.False(t, s.Contains(night))
+	assert.True(t, s.Contains(night.AddDate(0, 0, 2).Add(6*time.Hour)))
+	assertTime(t, time.Date(2026, time.November, 9, 8, 0, 0, 0, time.UTC), s.Next(night))
+	require.Len(t, s.Windows(night, night.AddDate(0, 0, 3)), 1)
+
+	disabled, err := NewScheduleFromMap(map[string]string{"mon-fri": "8-18", "enabled": "false"})
+	require.NoError(t, err)
+	s.Policy = &disabled
+	assert.True(t, s.LeavesCommits())
+	assert.True(t, s.Contains(night))
+}
+
+func TestContextPlainText(t *testing.T) {
+	options := map[string]string{
+		"weekdays":                     "9-17",
+		"url.*github.com/me/*.enabled": "false",
+		"branch.release/*.saturday":    "10-12",
+	}
+	rawC, err := GetGitRawConfig(&options)
+	require.NoError(t, err)
+
+	text := rawC.PlainText()
+	assert.Contains(t, text, "[decent \"branch.release/*\"]\n\tsaturday = 10-12\n")
+	assert.Contains(t, text, "[decent \"url.*github.com/me/*\"]\n\tenabled = false\n")
+
+	parsed, err := NewScheduleFromPlainText(strings.NewReader(text))
+	require.NoError(t, err)
+	assert.Equal(t, rawC.Contexts, parsed.Contexts)
+}
+
+func TestLintContext(t *testing.T) {
+	assert.NoError(t, Lint(map[string]string{"remote.origin.enabled": "false", "branch.main.monday": "9-17"}))
+
+	err := Lint(map[string]string{"remote.origin.enabeld": "false", "branch.main.monday": "9-25"})
+	assert.ErrorContains(t, err, "remote.origin.enabeld: unknown key, did you mean remote.origin.enabled?")
+	assert.ErrorContains(t, err, "hour out of range")
+	assert.ErrorContains(t, Lint(map[string]string{"remote.origin.enabled": "maybe"}), "expected true or false")
+}
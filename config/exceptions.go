@@ -0,0 +1,316 @@
+/* SPDX-License-Identifier: MIT */
+package config
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+// A calendar day, without time nor location
+type Date struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+type DateRange struct {
+	From Date
+	To   Date
+}
+
+func DateOf(t time.Time) Date {
+	y, m, d := t.Date()
+	return Date{Year: y, Month: m, Day: d}
+}
+
+func ParseDate(str string) (Date, error) {
+	t, err := time.Parse(dateLayout, str)
+	if err != nil {
+		return Date{}, fmt.Errorf("incorrect date format, expected 2006-01-02 but given %s", str)
+	}
+	return DateOf(t), nil
+}
+
+// Time returns the midnight of the date in the given location
+func (d Date) Time(loc *time.Location) time.Time {
+	return time.Date(d.Year, d.Month, d.Day, 0, 0, 0, 0, loc)
+}
+
+func (d Date) AddDays(days int) Date {
+	return DateOf(time.Date(d.Year, d.Month, d.Day+days, 0, 0, 0, 0, time.UTC))
+}
+
+func (d Date) Weekday() time.Weekday {
+	return d.Time(time.UTC).Weekday()
+}
+
+func (d Date) Before(o Date) bool {
+	return d.Time(time.UTC).Before(o.Time(time.UTC))
+}
+
+func (d Date) String() string {
+	return d.Time(time.UTC).Format(dateLayout)
+}
+
+func (r DateRange) Contains(d Date) bool {
+	return !d.Before(r.From) && !r.To.Before(d)
+}
+
+func (r DateRange) String() string {
+	if r.From == r.To {
+		return r.From.String()
+	}
+	return fmt.Sprintf("%s..%s", r.From, r.To)
+}
+
+// "2026-12-24..2026-12-26, 2027-01-01"
+func parseOff(rawOff string) ([]DateRange, error) {
+	if strings.TrimSpace(rawOff) == "" {
+		return nil, nil
+	}
+
+	errs := []error{}
+	ranges := []DateRange{}
+	for _, token := range strings.Split(rawOff, ",") {
+		token = strings.TrimSpace(token)
+		from, to, isRange := strings.Cut(token, "..")
+
+		fDate, err := ParseDate(from)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("off: %w", err))
+			continue
+		}
+
+		tDate := fDate
+		if isRange {
+			tDate, err = ParseDate(to)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("off: %w", err))
+				continue
+			}
+		}
+
+		if tDate.Before(fDate) {
+			errs = append(errs, fmt.Errorf("off: date range is inverse: %s", token))
+			continue
+		}
+
+		ranges = append(ranges, DateRange{From: fDate, To: tDate})
+	}
+
+	return ranges, errors.Join(errs...)
+}
+
+// "2026-11-02 10:00/12:00, 13:00/14:00, 2026-11-09 14:00/16:00"
+// Every token starting with a date begins the frames of that date
+func parseDateFrames(key string, rawDateFrames string) (map[Date]TimeFrames, error) {
+	if strings.TrimSpace(rawDateFrames) == "" {
+		return nil, nil
+	}
+
+	errs := []error{}
+	dateFrames := map[Date]TimeFrames{}
+	var current *Date
+	for _, token := range strings.Split(rawDateFrames, ",") {
+		token = strings.TrimSpace(token)
+		if len(token) >= len(dateLayout) {
+			if date, err := ParseDate(token[:len(dateLayout)]); err == nil {
+				current = &date
+				token = strings.TrimSpace(token[len(dateLayout):])
+				if _, exists := dateFrames[date]; exists {
+					errs = append(errs, fmt.Errorf("%s: %s is given more than once", key, date))
+				}
+				dateFrames[date] = TimeFrames{}
+			}
+		}
+
+		if current == nil {
+			errs = append(errs, fmt.Errorf("%s: expected a date before %s", key, token))
+			continue
+		}
+
+		if token == "" {
+			continue
+		}
+
+		frame, err := parseFrame(token)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s %s: %w", key, current, err))
+			continue
+		}
+		dateFrames[*current] = append(dateFrames[*current], frame)
+	}
+
+	for date, frames := range dateFrames {
+		if len(frames) == 0 {
+			errs = append(errs, fmt.Errorf("%s %s: no frames given, use off for days without frames", key, date))
+		}
+		sortFrames(frames)
+	}
+
+	return dateFrames, errors.Join(errs...)
+}
+
+func (s *Schedule) IsOff(date Date) bool {
+	for _, r := range s.Off {
+		if r.Contains(date) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Schedule) IsHoliday(date Date) bool {
+	if s.Holidays == nil {
+		return false
+	}
+	_, exists := s.Holidays.Holiday(date)
+	return exists
+}
+
+func (s *Schedule) isException(date Date) bool {
+	if _, exists := s.Overrides[date]; exists {
+		return true
+	}
+	if len(s.blocksOn(date)) > 0 || len(s.blocksOn(date.AddDays(-1))) > 0 {
+		return true
+	}
+	for _, rule := range s.Rules {
+		if rule.RRule.Matches(date) {
+			return true
+		}
+	}
+	return s.IsOff(date) || s.IsHoliday(date)
+}
+
+// FramesOn returns the frames starting on the given calendar date, taking
+// days off, overrides, holidays, rules and blocks into account. Overrides
+// win over holidays so it is possible to work on one. A rule taking the day
+// off wins over the ones with frames, from those the first one by name is used.
+func (s *Schedule) FramesOn(date Date) TimeFrames {
+	if s.IsOff(date) {
+		return nil
+	}
+	if frames, exists := s.Overrides[date]; exists {
+		return s.subtractBlocks(date, frames)
+	}
+	if s.IsHoliday(date) {
+		return nil
+	}
+
+	var frames TimeFrames
+	for _, rule := range s.Rules {
+		if rule.Block || !rule.RRule.Matches(date) {
+			continue
+		}
+		if rule.Off {
+			return nil
+		}
+		if frames == nil {
+			frames = rule.Frames
+		}
+	}
+	if frames == nil {
+		frames = s.Days[date.Weekday()].DecentFrames
+	}
+
+	return s.subtractBlocks(date, frames)
+}
+
+func (s *Schedule) blocksOn(date Date) TimeFrames {
+	blocks := s.Blocks[date]
+	for _, rule := range s.Rules {
+		if rule.Block && rule.RRule.Matches(date) {
+			blocks = append(blocks[:len(blocks):len(blocks)], rule.Frames...)
+		}
+	}
+	return blocks
+}
+
+// Blocks are relative to their date, the ones of the surrounding days are
+// shifted so they can cut overnight frames
+func (s *Schedule) subtractBlocks(date Date, frames TimeFrames) TimeFrames {
+	blocks := TimeFrames{}
+	for _, b := range s.blocksOn(date.AddDays(-1)) {
+		blocks = append(blocks, TimeFrame{StartMinute: b.StartMinute - dayMinutes, EndMinute: b.EndMinute - dayMinutes})
+	}
+	blocks = append(blocks, s.blocksOn(date)...)
+	for _, b := range s.blocksOn(date.AddDays(1)) {
+		blocks = append(blocks, TimeFrame{StartMinute: b.StartMinute + dayMinutes, EndMinute: b.EndMinute + dayMinutes})
+	}
+	if len(blocks) == 0 {
+		return frames
+	}
+
+	for _, b := range blocks {
+		remaining := TimeFrames{}
+		for _, f := range frames {
+			if b.EndMinute <= f.StartMinute || b.StartMinute > f.EndMinute {
+				remaining = append(remaining, f)
+				continue
+			}
+			if f.StartMinute < b.StartMinute {
+				remaining = append(remaining, TimeFrame{StartMinute: f.StartMinute, EndMinute: b.StartMinute - 1})
+			}
+			if b.EndMinute <= f.EndMinute {
+				remaining = append(remaining, TimeFrame{StartMinute: b.EndMinute, EndMinute: f.EndMinute})
+			}
+		}
+		frames = remaining
+	}
+
+	//A block can leave the remaining part of an overnight frame starting
+	//after midnight, it still belongs to this date
+	return frames
+}
+
+func (s *Schedule) containsSecond(date Date, second int) bool {
+	if !s.isException(date) && !s.isException(date.AddDays(-1)) {
+		return s.Days[date.Weekday()].Contains(second)
+	}
+	return s.spansOn(date).Contains(second)
+}
+
+// The decent time of a date, overnight frames of the day before included
+func (s *Schedule) spansOn(date Date) DaySpans {
+	spans := []DaySpan{}
+	for _, frame := range s.FramesOn(date) {
+		spans = append(spans, frame.span())
+	}
+	for _, frame := range s.FramesOn(date.AddDays(-1)) {
+		span := frame.span()
+		spans = append(spans, DaySpan{Start: span.Start - daySeconds, End: span.End - daySeconds})
+	}
+	return newDaySpans(spans)
+}
+
+// RawOff returns the ranges in the git config format
+func RawOff(ranges []DateRange) string {
+	raw := make([]string, len(ranges))
+	for k, r := range ranges {
+		raw[k] = r.String()
+	}
+	return strings.Join(raw, ", ")
+}
+
+// RawDateFrames returns the frames per date in the git config format
+func RawDateFrames(dateFrames map[Date]TimeFrames) string {
+	dates := make([]Date, 0, len(dateFrames))
+	for date := range dateFrames {
+		dates = append(dates, date)
+	}
+	sort.Slice(dates, func(i, j int) bool {
+		return dates[i].Before(dates[j])
+	})
+
+	raw := make([]string, len(dates))
+	for k, date := range dates {
+		raw[k] = fmt.Sprintf("%s %s", date, dateFrames[date].Raw())
+	}
+	return strings.Join(raw, ", ")
+}
@@ -0,0 +1,179 @@
+/* SPDX-License-Identifier: MIT */
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Scope of the values that come from IcsFile or the preset instead of git config
+const (
+	calendarOrigin = "calendar"
+	presetOrigin   = "preset"
+)
+
+// Export is the resolved schedule for scripts, every value comes with the
+// git config scope (local, global, system...) it was read from
+type Export struct {
+	Preset    *ExportedValue      `json:"preset,omitempty" yaml:"preset,omitempty"`
+	Timezone  *ExportedValue      `json:"timezone,omitempty" yaml:"timezone,omitempty"`
+	Days      []ExportedDay       `json:"days" yaml:"days"`
+	Holidays  *ExportedValue      `json:"holidays,omitempty" yaml:"holidays,omitempty"`
+	Off       []ExportedValue     `json:"off,omitempty" yaml:"off,omitempty"`
+	Overrides []ExportedDateFrame `json:"overrides,omitempty" yaml:"overrides,omitempty"`
+	Blocks    []ExportedDateFrame `json:"blocks,omitempty" yaml:"blocks,omitempty"`
+	Rules     []ExportedRule      `json:"rules,omitempty" yaml:"rules,omitempty"`
+	IcsFile   *ExportedValue      `json:"icsFile,omitempty" yaml:"icsFile,omitempty"`
+}
+
+type ExportedValue struct {
+	Value string `json:"value" yaml:"value"`
+	Scope string `json:"scope" yaml:"scope"`
+}
+
+type ExportedDay struct {
+	Day    string   `json:"day" yaml:"day"`
+	Frames []string `json:"frames" yaml:"frames"`
+	// Parts of the previous day overnight frames
+	Spillover []string `json:"spillover,omitempty" yaml:"spillover,omitempty"`
+	// Day used when this one has no frames
+	ClosestDecentDay string `json:"closestDecentDay" yaml:"closestDecentDay"`
+	Scope            string `json:"scope,omitempty" yaml:"scope,omitempty"`
+}
+
+type ExportedDateFrame struct {
+	Date   string   `json:"date" yaml:"date"`
+	Frames []string `json:"frames" yaml:"frames"`
+	Scope  string   `json:"scope" yaml:"scope"`
+}
+
+type ExportedRule struct {
+	Name  string `json:"name" yaml:"name"`
+	Rule  string `json:"rule" yaml:"rule"`
+	Scope string `json:"scope" yaml:"scope"`
+}
+
+// Export resolves the schedule, scopes are the ones of each [decent] key
+// as given by git config --show-scope
+func (s *Schedule) Export(scopes map[string]string) Export {
+	daySources := dayScopes(scopes)
+	e := Export{Days: []ExportedDay{}}
+
+	if s.Preset != "" {
+		e.Preset = &ExportedValue{Value: s.Preset, Scope: scopes["preset"]}
+	}
+	if s.Location != nil {
+		e.Timezone = &ExportedValue{Value: s.Location.String(), Scope: scopes["timezone"]}
+	}
+	for _, d := range weekdayOrder {
+		day := s.Days[d]
+		exported := ExportedDay{
+			Day:              d.String(),
+			Frames:           rawFrames(day.DecentFrames),
+			Spillover:        rawFrames(day.SpilloverFrames),
+			ClosestDecentDay: day.ClosestDecentDay.String(),
+		}
+		if len(day.DecentFrames) > 0 {
+			exported.Scope = daySources[d]
+			if day.origin != "" {
+				exported.Scope = day.origin
+			}
+		}
+		e.Days = append(e.Days, exported)
+	}
+	if s.Holidays != nil {
+		e.Holidays = &ExportedValue{Value: strings.Join(s.Holidays.Regions, ", "), Scope: scopes["holidays"]}
+	}
+
+	configuredOff, configuredRules := len(s.Off), len(s.Rules)
+	var calendarBlocks map[Date]TimeFrames
+	if s.calendar != nil {
+		configuredOff -= len(s.calendar.Off)
+		configuredRules -= len(s.calendar.Rules)
+		calendarBlocks = s.calendar.Blocks
+	}
+	for k, off := range s.Off {
+		scope := scopes["off"]
+		if k >= configuredOff {
+			scope = calendarOrigin
+		}
+		e.Off = append(e.Off, ExportedValue{Value: off.String(), Scope: scope})
+	}
+
+	for _, date := range sortedDates(s.Overrides) {
+		e.Overrides = append(e.Overrides, ExportedDateFrame{Date: date.String(), Frames: rawFrames(s.Overrides[date]), Scope: scopes["override"]})
+	}
+	blocks := withoutFrames(s.Blocks, calendarBlocks)
+	for _, date := range sortedDates(blocks) {
+		e.Blocks = append(e.Blocks, ExportedDateFrame{Date: date.String(), Frames: rawFrames(blocks[date]), Scope: scopes["block"]})
+	}
+	for _, date := range sortedDates(calendarBlocks) {
+		e.Blocks = append(e.Blocks, ExportedDateFrame{Date: date.String(), Frames: rawFrames(calendarBlocks[date]), Scope: calendarOrigin})
+	}
+
+	for k, rule := range s.Rules {
+		scope := scopes["rule."+rule.Name]
+		if k >= configuredRules {
+			scope = calendarOrigin
+		}
+		e.Rules = append(e.Rules, ExportedRule{Name: rule.Name, Rule: rule.Raw(), Scope: scope})
+	}
+
+	if s.IcsFile != "" {
+		e.IcsFile = &ExportedValue{Value: s.IcsFile, Scope: scopes["icsfile"]}
+	}
+
+	return e
+}
+
+// ExportINI returns the configuration of the schedule in the git config
+// format, the scopes and the fallback of days without frames are comments
+func (s *Schedule) ExportINI(scopes map[string]string) string {
+	rawC := s.Raw()
+	daySources := dayScopes(scopes)
+
+	comments := map[string]string{}
+	for _, option := range rawC.Options() {
+		comments[option.Key] = scopes[strings.ToLower(option.Key)]
+		if d, isDay := dayNames[strings.ToLower(option.Key)]; isDay {
+			comments[option.Key] = daySources[d]
+		}
+	}
+
+	text := rawC.plainText(comments)
+	for _, d := range weekdayOrder {
+		if len(s.Days[d].DecentFrames) == 0 {
+			text += fmt.Sprintf("# %s has no frames, commits go to %s\n", d, s.Days[d].ClosestDecentDay)
+		}
+	}
+	return text
+}
+
+// DayKeys returns the key setting each weekday, single days win over ranges
+func DayKeys(options map[string]string) map[time.Weekday]string {
+	days := map[time.Weekday]string{}
+	for _, key := range sortKeysByDays(options) {
+		for _, d := range daysOf(key) {
+			days[d] = key
+		}
+	}
+	return days
+}
+
+// The scope of the key setting each day
+func dayScopes(scopes map[string]string) map[time.Weekday]string {
+	days := map[time.Weekday]string{}
+	for d, key := range DayKeys(scopes) {
+		days[d] = scopes[key]
+	}
+	return days
+}
+
+func rawFrames(frames TimeFrames) []string {
+	raw := make([]string, len(frames))
+	for k, frame := range frames {
+		raw[k] = frame.Raw()
+	}
+	return raw
+}
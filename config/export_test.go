@@ -0,0 +1,79 @@
+/* SPDX-License-Identifier: MIT */
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExport(t *testing.T) {
+	options := map[string]string{
+		"weekdays":        "9-17",
+		"fri":             "9-13",
+		"sunday":          "22-2",
+		"timezone":        "Europe/Berlin",
+		"off":             "2026-12-24",
+		"rule.nineeighty": "FREQ=WEEKLY;BYDAY=FR off",
+	}
+	scopes := map[string]string{
+		"weekdays":        "global",
+		"fri":             "local",
+		"sunday":          "local",
+		"timezone":        "global",
+		"off":             "local",
+		"rule.nineeighty": "local",
+	}
+	s, err := NewScheduleFromMap(options)
+	require.NoError(t, err)
+
+	e := s.Export(scopes)
+	assert.Equal(t, &ExportedValue{Value: "Europe/Berlin", Scope: "global"}, e.Timezone)
+	assert.Equal(t, []ExportedDay{
+		{Day: "Monday", Frames: []string{"09:00/17:00"}, Spillover: []string{"00:00/02:00"}, ClosestDecentDay: "Monday", Scope: "global"},
+		{Day: "Tuesday", Frames: []string{"09:00/17:00"}, Spillover: []string{}, ClosestDecentDay: "Tuesday", Scope: "global"},
+		{Day: "Wednesday", Frames: []string{"09:00/17:00"}, Spillover: []string{}, ClosestDecentDay: "Wednesday", Scope: "global"},
+		{Day: "Thursday", Frames: []string{"09:00/17:00"}, Spillover: []string{}, ClosestDecentDay: "Thursday", Scope: "global"},
+		{Day: "Friday", Frames: []string{"09:00/13:00"}, Spillover: []string{}, ClosestDecentDay: "Friday", Scope: "local"},
+		{Day: "Saturday", Frames: []string{}, Spillover: []string{}, ClosestDecentDay: "Sunday"},
+		{Day: "Sunday", Frames: []string{"22:00/02:00"}, Spillover: []string{}, ClosestDecentDay: "Sunday", Scope: "local"},
+	}, e.Days)
+	assert.Equal(t, []ExportedValue{{Value: "2026-12-24", Scope: "local"}}, e.Off)
+	assert.Equal(t, []ExportedRule{{Name: "nineeighty", Rule: "FREQ=WEEKLY;BYDAY=FR off", Scope: "local"}}, e.Rules)
+
+	assert.Equal(t, `[decent]
+	Monday = 09:00/17:00 # global
+	Tuesday = 09:00/17:00 # global
+	Wednesday = 09:00/17:00 # global
+	Thursday = 09:00/17:00 # global
+	Friday = 09:00/13:00 # local
+	Sunday = 22:00/02:00 # local
+	Timezone = Europe/Berlin # global
+	Off = 2026-12-24 # local
+[decent "rule"]
+	nineeighty = "FREQ=WEEKLY;BYDAY=FR off" # local
+# Saturday has no frames, commits go to Sunday
+`, s.ExportINI(scopes))
+}
+
+func TestExportCalendar(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "calendar.ics")
+	require.NoError(t, os.WriteFile(path, []byte(icsFixture), 0644))
+
+	s, err := NewScheduleFromMap(map[string]string{"monday": "10-18", "icsfile": path})
+	require.NoError(t, err)
+
+	e := s.Export(map[string]string{"monday": "local", "icsfile": "global"})
+	assert.Equal(t, "local", e.Days[0].Scope)
+	assert.Equal(t, "calendar", e.Days[1].Scope)
+	assert.Equal(t, &ExportedValue{Value: path, Scope: "global"}, e.IcsFile)
+	for _, off := range e.Off {
+		assert.Equal(t, "calendar", off.Scope)
+	}
+	for _, block := range e.Blocks {
+		assert.Equal(t, "calendar", block.Scope)
+	}
+}
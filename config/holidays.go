@@ -0,0 +1,337 @@
+/* SPDX-License-Identifier: MIT */
+package config
+
+import (
+	"bufio"
+	_ "embed"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed holidays.ini
+var holidaysData string
+
+var holidayRules, holidayRulesErr = parseHolidayRules(holidaysData)
+
+type Holiday struct {
+	Date   Date
+	Name   string
+	Region string
+}
+
+type holidayRule struct {
+	name string
+	// Single date when year is set, fixed or anchor date otherwise
+	year  int
+	month time.Month
+	day   int
+	// Days after easter sunday
+	easter       bool
+	easterOffset int
+	// First weekday on or after (before when !after) the anchor date
+	weekday    time.Weekday
+	hasWeekday bool
+	after      bool
+
+	observed   bool
+	substitute bool
+	since      int
+}
+
+// A set of regions whose holidays are computed per year on demand
+type HolidayCalendar struct {
+	Regions []string
+	years   map[int]map[Date]Holiday
+}
+
+// HolidayRegions returns the supported region codes
+func HolidayRegions() []string {
+	regions := make([]string, 0, len(holidayRules))
+	for region := range holidayRules {
+		regions = append(regions, region)
+	}
+	sort.Strings(regions)
+	return regions
+}
+
+// "DE-BE, US"
+func NewHolidayCalendar(rawRegions string) (*HolidayCalendar, error) {
+	if holidayRulesErr != nil {
+		return nil, fmt.Errorf("embedded holiday data is broken: %w", holidayRulesErr)
+	}
+
+	c := &HolidayCalendar{years: map[int]map[Date]Holiday{}}
+	for _, region := range strings.Split(rawRegions, ",") {
+		region = strings.ToUpper(strings.TrimSpace(region))
+		if region == "" {
+			continue
+		}
+		if _, exists := holidayRules[region]; !exists {
+			return nil, fmt.Errorf("unknown holiday region %s, supported: %s", region, strings.Join(HolidayRegions(), ", "))
+		}
+		c.Regions = append(c.Regions, region)
+	}
+
+	return c, nil
+}
+
+func (c *HolidayCalendar) Holiday(date Date) (Holiday, bool) {
+	h, exists := c.year(date.Year)[date]
+	return h, exists
+}
+
+// Between returns the holidays from one date to another, both included
+func (c *HolidayCalendar) Between(from Date, to Date) []Holiday {
+	holidays := []Holiday{}
+	for year := from.Year; year <= to.Year; year++ {
+		for date, h := range c.year(year) {
+			if !date.Before(from) && !to.Before(date) {
+				holidays = append(holidays, h)
+			}
+		}
+	}
+	sort.Slice(holidays, func(i, j int) bool {
+		return holidays[i].Date.Before(holidays[j].Date)
+	})
+	return holidays
+}
+
+func (c *HolidayCalendar) year(year int) map[Date]Holiday {
+	if holidays, exists := c.years[year]; exists {
+		return holidays
+	}
+
+	holidays := map[Date]Holiday{}
+	for _, region := range c.Regions {
+		//Observed dates might move holidays to the previous or next year
+		for y := year - 1; y <= year+1; y++ {
+			for date, name := range regionHolidays(holidayRules[region], y) {
+				if date.Year != year {
+					continue
+				}
+				if _, exists := holidays[date]; !exists {
+					holidays[date] = Holiday{Date: date, Name: name, Region: region}
+				}
+			}
+		}
+	}
+
+	c.years[year] = holidays
+	return holidays
+}
+
+func regionHolidays(rules []holidayRule, year int) map[Date]string {
+	holidays := map[Date]string{}
+	substitutes := []holidayRule{}
+	for _, rule := range rules {
+		date, ok := rule.dateIn(year)
+		if !ok {
+			continue
+		}
+		if rule.substitute && isWeekend(date) {
+			substitutes = append(substitutes, rule)
+			continue
+		}
+		if rule.observed {
+			switch date.Weekday() {
+			case time.Saturday:
+				date = date.AddDays(-1)
+			case time.Sunday:
+				date = date.AddDays(1)
+			}
+		}
+		holidays[date] = rule.name
+	}
+
+	//Substitutes go to the first weekday not already taken by a holiday
+	for _, rule := range substitutes {
+		date, _ := rule.dateIn(year)
+		for _, taken := holidays[date]; taken || isWeekend(date); _, taken = holidays[date] {
+			date = date.AddDays(1)
+		}
+		holidays[date] = rule.name
+	}
+
+	return holidays
+}
+
+func isWeekend(date Date) bool {
+	return date.Weekday() == time.Saturday || date.Weekday() == time.Sunday
+}
+
+func (r holidayRule) dateIn(year int) (Date, bool) {
+	if year < r.since || (r.year != 0 && r.year != year) {
+		return Date{}, false
+	}
+
+	if r.easter {
+		return easterSunday(year).AddDays(r.easterOffset), true
+	}
+
+	date := Date{Year: year, Month: r.month, Day: r.day}
+	if !r.hasWeekday {
+		return date, true
+	}
+
+	for date.Weekday() != r.weekday {
+		if r.after {
+			date = date.AddDays(1)
+		} else {
+			date = date.AddDays(-1)
+		}
+	}
+	return date, true
+}
+
+// Anonymous Gregorian algorithm
+func easterSunday(year int) Date {
+	a := year % 19
+	b := year / 100
+	c := year % 100
+	d := b / 4
+	e := b % 4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i := c / 4
+	k := c % 4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := ((h + l - 7*m + 114) % 31) + 1
+
+	return Date{Year: year, Month: time.Month(month), Day: day}
+}
+
+var shortWeekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+func parseHolidayRules(data string) (map[string][]holidayRule, error) {
+	regions := map[string][]holidayRule{}
+	errs := []error{}
+	region := ""
+	n := 0
+
+	s := bufio.NewScanner(strings.NewReader(data))
+	for s.Scan() {
+		n++
+		line := s.Text()
+		if i := strings.Index(line, "#"); i != -1 {
+			line = line[:i]
+		}
+
+		line = strings.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			region = strings.ToUpper(line[1 : len(line)-1])
+			regions[region] = []holidayRule{}
+			continue
+		}
+
+		rawRule, name, found := strings.Cut(line, "=")
+		if !found || region == "" {
+			errs = append(errs, fmt.Errorf("line %d: expected rule = name inside a region", n))
+			continue
+		}
+		rawRule = strings.TrimSpace(rawRule)
+		name = strings.TrimSpace(name)
+
+		if rawRule == "include" {
+			included, exists := regions[strings.ToUpper(name)]
+			if !exists {
+				errs = append(errs, fmt.Errorf("line %d: region %s must be defined before being included", n, name))
+				continue
+			}
+			regions[region] = append(regions[region], included...)
+			continue
+		}
+
+		rule, err := parseHolidayRule(rawRule)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("line %d: %w", n, err))
+			continue
+		}
+		rule.name = name
+		regions[region] = append(regions[region], rule)
+	}
+
+	return regions, errors.Join(errs...)
+}
+
+func parseHolidayRule(rawRule string) (holidayRule, error) {
+	rule := holidayRule{}
+	fields := strings.Fields(rawRule)
+
+	for k := 1; k < len(fields); k++ {
+		switch fields[k] {
+		case "observed":
+			rule.observed = true
+		case "substitute":
+			rule.substitute = true
+		case "since":
+			if k+1 >= len(fields) {
+				return rule, fmt.Errorf("since requires a year")
+			}
+			year, err := strconv.Atoi(fields[k+1])
+			if err != nil {
+				return rule, fmt.Errorf("invalid since year %s", fields[k+1])
+			}
+			rule.since = year
+			k++
+		default:
+			return rule, fmt.Errorf("unknown modifier %s", fields[k])
+		}
+	}
+
+	date := fields[0]
+	if offset, found := strings.CutPrefix(date, "easter"); found {
+		days, err := strconv.Atoi(offset)
+		if err != nil {
+			return rule, fmt.Errorf("invalid easter offset %s", offset)
+		}
+		rule.easter = true
+		rule.easterOffset = days
+		return rule, nil
+	}
+
+	if i := strings.IndexAny(date, "<>"); i != -1 {
+		weekday, exists := shortWeekdays[date[i+1:]]
+		if !exists {
+			return rule, fmt.Errorf("invalid weekday %s", date[i+1:])
+		}
+		rule.hasWeekday = true
+		rule.weekday = weekday
+		rule.after = date[i] == '>'
+		date = date[:i]
+	}
+
+	layout := "01-02"
+	if len(date) == len(dateLayout) {
+		layout = dateLayout
+	}
+	t, err := time.Parse(layout, date)
+	if err != nil {
+		return rule, fmt.Errorf("invalid date %s", date)
+	}
+	if layout == dateLayout {
+		rule.year = t.Year()
+	}
+	rule.month = t.Month()
+	rule.day = t.Day()
+
+	return rule, nil
+}
@@ -0,0 +1,108 @@
+/* SPDX-License-Identifier: MIT */
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmbeddedHolidays(t *testing.T) {
+	require.NoError(t, holidayRulesErr)
+	assert.Contains(t, HolidayRegions(), "DE-BE")
+	assert.Contains(t, HolidayRegions(), "US")
+}
+
+func TestEasterSunday(t *testing.T) {
+	assert.Equal(t, Date{2024, time.March, 31}, easterSunday(2024))
+	assert.Equal(t, Date{2025, time.April, 20}, easterSunday(2025))
+	assert.Equal(t, Date{2026, time.April, 5}, easterSunday(2026))
+	assert.Equal(t, Date{2038, time.April, 25}, easterSunday(2038))
+}
+
+func TestHolidayCalendar(t *testing.T) {
+	c, err := NewHolidayCalendar("de-be, US")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"DE-BE", "US"}, c.Regions)
+
+	tests := []struct {
+		date Date
+		name string
+	}{
+		{Date{2026, time.April, 3}, "Good Friday"},
+		{Date{2026, time.March, 8}, "International Women's Day"},
+		{Date{2026, time.May, 14}, "Ascension Day"},
+		{Date{2026, time.November, 26}, "Thanksgiving Day"},
+		//Independence day is on Saturday
+		{Date{2026, time.July, 3}, "Independence Day"},
+		//Next year new year's day is on Saturday
+		{Date{2021, time.December, 31}, "New Year's Day"},
+	}
+	for _, tc := range tests {
+		h, exists := c.Holiday(tc.date)
+		assert.Truef(t, exists, "%s should be a holiday", tc.date)
+		assert.Equal(t, tc.name, h.Name)
+	}
+
+	_, exists := c.Holiday(Date{2018, time.March, 8})
+	assert.False(t, exists, "Women's day is a holiday since 2019")
+
+	//Christmas is shared by both regions, listed once
+	holidays := c.Between(Date{2026, time.December, 20}, Date{2027, time.January, 5})
+	require.Len(t, holidays, 3)
+	assert.Equal(t, Date{2026, time.December, 25}, holidays[0].Date)
+	assert.Equal(t, Date{2027, time.January, 1}, holidays[2].Date)
+
+	_, err = NewHolidayCalendar("XX-YY")
+	assert.ErrorContains(t, err, "unknown holiday region XX-YY")
+}
+
+func TestHolidaySubstitutes(t *testing.T) {
+	c, err := NewHolidayCalendar("GB-ENG")
+	require.NoError(t, err)
+
+	//Christmas on Saturday, boxing day on Sunday
+	holidays := c.Between(Date{2027, time.December, 24}, Date{2027, time.December, 31})
+	require.Len(t, holidays, 2)
+	assert.Equal(t, Date{2027, time.December, 27}, holidays[0].Date)
+	assert.Equal(t, Date{2027, time.December, 28}, holidays[1].Date)
+
+	//Christmas on Sunday, boxing day on Monday
+	holidays = c.Between(Date{2022, time.December, 24}, Date{2022, time.December, 31})
+	require.Len(t, holidays, 2)
+	assert.Equal(t, "Boxing Day", holidays[0].Name)
+	assert.Equal(t, Date{2022, time.December, 27}, holidays[1].Date)
+
+	c, err = NewHolidayCalendar("DE-SN")
+	require.NoError(t, err)
+	h, exists := c.Holiday(Date{2026, time.November, 18})
+	assert.True(t, exists)
+	assert.Equal(t, "Day of Repentance and Prayer", h.Name)
+}
+
+func TestScheduleSkipsHolidays(t *testing.T) {
+	raw := RawScheduleConfig{
+		Days: map[time.Weekday]string{
+			time.Monday: "09:00/17:00",
+			time.Friday: "09:00/17:00",
+		},
+		Holidays: "DE",
+		Override: "2026-12-25 10:00/11:00",
+	}
+	s, err := NewScheduleFromRaw(&raw)
+	require.NoError(t, err)
+
+	assert.Empty(t, s.FramesOn(Date{2026, time.April, 3}), "Good Friday")
+	assert.Empty(t, s.FramesOn(Date{2026, time.April, 6}), "Easter Monday")
+	assert.Len(t, s.FramesOn(Date{2026, time.December, 25}), 1, "Overrides win over holidays")
+
+	//Good Friday moves to the Friday after Easter Monday
+	pTime := time.Date(2026, time.April, 3, 10, 0, 0, 0, time.UTC)
+	assertTime(t, pTime.Add(7*24*time.Hour-time.Hour), s.Next(pTime))
+
+	raw.Holidays = "Atlantis"
+	_, err = NewScheduleFromRaw(&raw)
+	assert.ErrorContains(t, err, "unknown holiday region")
+}
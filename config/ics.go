@@ -0,0 +1,489 @@
+/* SPDX-License-Identifier: MIT */
+package config
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Decent information extracted from an iCalendar file
+type IcsCalendar struct {
+	// Weekly frames from recurring working hours events
+	WorkingHours map[time.Weekday]TimeFrames
+	// All day busy or out of office events
+	Off []DateRange
+	// Busy or out of office time within a day
+	Blocks map[Date]TimeFrames
+	// Recurring busy or out of office events
+	Rules []Rule
+	// Summaries of the recurring events whose recurrence is not supported
+	Skipped []string
+}
+
+type icsProperty struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+type icsEvent map[string]icsProperty
+
+// Longest line read from an ics file, before unfolding
+const maxIcsLine = 16 * 1024 * 1024
+
+var workingHoursSummary = regexp.MustCompile(`(?i)(working|work|office) hours`)
+
+func LoadIcsFile(path string, loc *time.Location) (*IcsCalendar, error) {
+	if home, found := strings.CutPrefix(path, "~/"); found {
+		dir, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("couldn't expand %s: %w", path, err)
+		}
+		path = filepath.Join(dir, home)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't open ics file %w", err)
+	}
+	defer f.Close()
+
+	return ParseIcs(f, loc)
+}
+
+// ParseIcs reads the events of an iCalendar, floating times and dates are
+// read in loc (or the local timezone when nil) as are timed events converted to
+func ParseIcs(r io.Reader, loc *time.Location) (*IcsCalendar, error) {
+	if loc == nil {
+		loc = time.Local
+	}
+
+	events, err := parseIcsEvents(r)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &IcsCalendar{
+		WorkingHours: map[time.Weekday]TimeFrames{},
+		Blocks:       map[Date]TimeFrames{},
+	}
+
+	//Occurrences moved or cancelled by events with the same UID
+	overridden := map[string][]time.Time{}
+	for _, event := range events {
+		if _, exists := event["RECURRENCE-ID"]; exists {
+			if id, _, err := event.time("RECURRENCE-ID", loc); err == nil {
+				overridden[event.value("UID")] = append(overridden[event.value("UID")], id)
+			}
+		}
+	}
+
+	errs := []error{}
+	for _, event := range events {
+		if event.value("STATUS") == "CANCELLED" {
+			continue
+		}
+
+		start, allDay, err := event.time("DTSTART", loc)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		end, err := event.end(start, allDay, loc)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		if _, recurring := event["RRULE"]; recurring {
+			exDates, err := event.exDates(loc, overridden[event.value("UID")])
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			if workingHoursSummary.MatchString(event.value("SUMMARY")) {
+				err = c.addWorkingHours(event, start, end, loc, exDates)
+				if err != nil {
+					errs = append(errs, err)
+				}
+				continue
+			}
+			if event.busy() {
+				c.addRule(event, start, end, loc, allDay, exDates)
+			}
+			continue
+		}
+
+		if !event.busy() {
+			continue
+		}
+
+		if allDay {
+			c.Off = append(c.Off, DateRange{From: DateOf(start), To: DateOf(end).AddDays(-1)})
+			continue
+		}
+		c.addBlock(start.In(loc), end.In(loc))
+	}
+
+	for _, frames := range c.WorkingHours {
+		sortFrames(frames)
+	}
+	for _, frames := range c.Blocks {
+		sortFrames(frames)
+	}
+	sort.Slice(c.Off, func(i, j int) bool {
+		return c.Off[i].From.Before(c.Off[j].From)
+	})
+
+	return c, errors.Join(errs...)
+}
+
+// Timed events are blocks on each day they cover, full days are off
+func (c *IcsCalendar) addBlock(start time.Time, end time.Time) {
+	for date := DateOf(start); !DateOf(end).Before(date); date = date.AddDays(1) {
+		from := 0
+		if date == DateOf(start) {
+			from = DayMinute(start)
+		}
+		to := dayMinutes
+		if date == DateOf(end) {
+			to = DayMinute(end)
+		}
+		if to <= from {
+			continue
+		}
+		if from == 0 && to == dayMinutes {
+			c.Off = append(c.Off, DateRange{From: date, To: date})
+			continue
+		}
+		c.Blocks[date] = append(c.Blocks[date], TimeFrame{StartMinute: from, EndMinute: to})
+	}
+}
+
+// Working hours are read in loc, skipped occurrences are blocks of that day
+func (c *IcsCalendar) addWorkingHours(event icsEvent, start time.Time, end time.Time, loc *time.Location, exDates []Date) error {
+	rrule, err := event.rrule(start, loc)
+	if err != nil {
+		return fmt.Errorf("working hours: %w", err)
+	}
+	if rrule.Freq != Weekly {
+		return fmt.Errorf("working hours: only weekly recurrences are supported")
+	}
+
+	start, end = start.In(loc), end.In(loc)
+	frame := TimeFrame{StartMinute: DayMinute(start), EndMinute: DayMinute(end)}
+	if frame.StartMinute > frame.EndMinute {
+		frame.EndMinute += dayMinutes
+	}
+
+	weekdays := []time.Weekday{start.Weekday()}
+	if len(rrule.ByDay) > 0 {
+		weekdays = weekdays[:0]
+		for _, wd := range rrule.ByDay {
+			weekdays = append(weekdays, wd.Weekday)
+		}
+	}
+
+	for _, weekday := range weekdays {
+		c.WorkingHours[weekday] = append(c.WorkingHours[weekday], frame)
+	}
+	for _, date := range exDates {
+		c.addBlock(date.Time(loc).Add(time.Duration(frame.StartMinute)*time.Minute), date.Time(loc).Add(time.Duration(frame.EndMinute)*time.Minute))
+	}
+	return nil
+}
+
+// Recurring all day events are days off, the rest are blocks
+func (c *IcsCalendar) addRule(event icsEvent, start time.Time, end time.Time, loc *time.Location, allDay bool, exDates []Date) {
+	summary := event.value("SUMMARY")
+	rrule, err := event.rrule(start, loc)
+	if err != nil || end.Sub(start) > 24*time.Hour {
+		c.Skipped = append(c.Skipped, summary)
+		return
+	}
+	rrule.ExDates = exDates
+	if !allDay {
+		start, end = start.In(loc), end.In(loc)
+	}
+
+	rule := Rule{Name: c.ruleName(summary), RRule: rrule, Off: allDay}
+	if !allDay {
+		frame := TimeFrame{StartMinute: DayMinute(start), EndMinute: DayMinute(end)}
+		if frame.StartMinute >= frame.EndMinute {
+			frame.EndMinute += dayMinutes
+		}
+		rule.Block = true
+		rule.Frames = TimeFrames{frame}
+	}
+	c.Rules = append(c.Rules, rule)
+}
+
+var notKeyChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Rule names are git config keys, alphanumeric and - starting with a letter
+func (c *IcsCalendar) ruleName(summary string) string {
+	name := "ics-" + strings.Trim(notKeyChars.ReplaceAllString(strings.ToLower(summary), "-"), "-")
+	name = strings.TrimSuffix(name, "-")
+
+	unique := name
+	for n := 2; ; n++ {
+		taken := false
+		for _, rule := range c.Rules {
+			taken = taken || rule.Name == unique
+		}
+		if !taken {
+			return unique
+		}
+		unique = fmt.Sprintf("%s-%d", name, n)
+	}
+}
+
+// RawWorkingHours returns the working hours in the git config format
+func (c *IcsCalendar) RawWorkingHours() map[time.Weekday]string {
+	raw := map[time.Weekday]string{}
+	for weekday, frames := range c.WorkingHours {
+		raw[weekday] = frames.Raw()
+	}
+	return raw
+}
+
+var icsWeekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// The recurrence starts with the event, read in loc. Weekdays move with the
+// event when in loc it is on another day.
+func (e icsEvent) rrule(start time.Time, loc *time.Location) (*RRule, error) {
+	rrule, err := ParseRRule(e.value("RRULE") + ";DTSTART=" + start.In(loc).Format("20060102"))
+	if err != nil {
+		return nil, err
+	}
+
+	shift := (int(start.In(loc).Weekday()) - int(start.Weekday()) + 7) % 7
+	for k, wd := range rrule.ByDay {
+		rrule.ByDay[k].Weekday = time.Weekday((int(wd.Weekday) + shift) % 7)
+	}
+	return rrule, nil
+}
+
+// The dates of the occurrences skipped with EXDATE or overridden by other
+// events, in loc
+func (e icsEvent) exDates(loc *time.Location, overridden []time.Time) ([]Date, error) {
+	times := overridden
+	if p, exists := e["EXDATE"]; exists {
+		for _, value := range strings.Split(p.value, ",") {
+			exDate := icsEvent{"SUMMARY": e["SUMMARY"], "EXDATE": icsProperty{name: p.name, params: p.params, value: value}}
+			t, _, err := exDate.time("EXDATE", loc)
+			if err != nil {
+				return nil, err
+			}
+			times = append(times, t)
+		}
+	}
+
+	dates := []Date{}
+	for _, t := range times {
+		dates = append(dates, DateOf(t.In(loc)))
+	}
+	return dates, nil
+}
+
+func (e icsEvent) value(name string) string {
+	return e[name].value
+}
+
+// Busy unless marked as transparent, free or tentative
+func (e icsEvent) busy() bool {
+	if e.value("TRANSP") == "TRANSPARENT" {
+		return false
+	}
+	switch e.value("X-MICROSOFT-CDO-BUSYSTATUS") {
+	case "FREE", "TENTATIVE":
+		return false
+	}
+	return true
+}
+
+func (e icsEvent) time(name string, loc *time.Location) (time.Time, bool, error) {
+	p, exists := e[name]
+	if !exists {
+		return time.Time{}, false, fmt.Errorf("event %s without %s", e.value("SUMMARY"), name)
+	}
+
+	if p.params["VALUE"] == "DATE" || len(p.value) == len("20060102") {
+		t, err := time.ParseInLocation("20060102", p.value, loc)
+		if err != nil {
+			return t, true, fmt.Errorf("event %s with invalid %s: %s", e.value("SUMMARY"), name, p.value)
+		}
+		return t, true, nil
+	}
+
+	tLoc := loc
+	if tzid, exists := p.params["TZID"]; exists {
+		//Unknown (non IANA) zones are read as floating times
+		if l, err := time.LoadLocation(tzid); err == nil {
+			tLoc = l
+		}
+	}
+
+	layout := "20060102T150405"
+	if strings.HasSuffix(p.value, "Z") {
+		layout = "20060102T150405Z"
+		tLoc = time.UTC
+	}
+	t, err := time.ParseInLocation(layout, p.value, tLoc)
+	if err != nil {
+		return t, false, fmt.Errorf("event %s with invalid %s: %s", e.value("SUMMARY"), name, p.value)
+	}
+	return t, false, nil
+}
+
+func (e icsEvent) end(start time.Time, allDay bool, loc *time.Location) (time.Time, error) {
+	if _, exists := e["DTEND"]; exists {
+		end, _, err := e.time("DTEND", loc)
+		return end, err
+	}
+
+	if duration, exists := e["DURATION"]; exists {
+		d, err := parseIcsDuration(duration.value)
+		if err != nil {
+			return start, fmt.Errorf("event %s: %w", e.value("SUMMARY"), err)
+		}
+		return start.Add(d), nil
+	}
+
+	if allDay {
+		return start.AddDate(0, 0, 1), nil
+	}
+	return start, nil
+}
+
+var icsDuration = regexp.MustCompile(`^P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+// P1W, P2D, PT1H30M
+func parseIcsDuration(str string) (time.Duration, error) {
+	m := icsDuration.FindStringSubmatch(str)
+	if m == nil {
+		return 0, fmt.Errorf("invalid duration %s", str)
+	}
+
+	units := []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second}
+	var d time.Duration
+	for k, unit := range units {
+		var n int
+		if m[k+1] != "" {
+			fmt.Sscanf(m[k+1], "%d", &n)
+		}
+		d += time.Duration(n) * unit
+	}
+	return d, nil
+}
+
+func parseIcsEvents(r io.Reader) ([]icsEvent, error) {
+	lines, err := unfoldIcs(r)
+	if err != nil {
+		return nil, err
+	}
+
+	events := []icsEvent{}
+	var event icsEvent
+	//Components nested inside events (alarms) are skipped
+	nested := 0
+	for _, line := range lines {
+		p, err := parseIcsProperty(line)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case p.name == "BEGIN" && p.value == "VEVENT":
+			event = icsEvent{}
+		case p.name == "END" && p.value == "VEVENT":
+			if event != nil {
+				events = append(events, event)
+			}
+			event = nil
+		case p.name == "BEGIN" && event != nil:
+			nested++
+		case p.name == "END" && event != nil:
+			nested--
+		case event != nil && nested == 0 && p.name == "EXDATE" && event[p.name].value != "":
+			//Skipped dates can be spread in several lines
+			p.value = event[p.name].value + "," + p.value
+			event[p.name] = p
+		case event != nil && nested == 0:
+			event[p.name] = p
+		}
+	}
+
+	return events, nil
+}
+
+// Long lines are folded with a CRLF followed by a space or a tab
+func unfoldIcs(r io.Reader) ([]string, error) {
+	lines := []string{}
+	s := bufio.NewScanner(r)
+	//Exported calendars can have lines longer than the default limit
+	s.Buffer(make([]byte, 0, 64*1024), maxIcsLine)
+	for s.Scan() {
+		line := strings.TrimRight(s.Text(), "\r")
+		if len(line) == 0 {
+			continue
+		}
+		if (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	if err := s.Err(); err != nil {
+		return nil, fmt.Errorf("couldn't read the ics file %w", err)
+	}
+	return lines, nil
+}
+
+// DTSTART;TZID=Europe/Berlin:20261102T130000
+func parseIcsProperty(line string) (icsProperty, error) {
+	p := icsProperty{params: map[string]string{}}
+
+	//Colons can be part of quoted parameters
+	quoted := false
+	colon := -1
+	for k, c := range line {
+		if c == '"' {
+			quoted = !quoted
+		}
+		if c == ':' && !quoted {
+			colon = k
+			break
+		}
+	}
+	if colon == -1 {
+		return p, fmt.Errorf("invalid ics line %s", line)
+	}
+
+	p.value = line[colon+1:]
+	parts := strings.Split(line[:colon], ";")
+	p.name = strings.ToUpper(parts[0])
+	for _, param := range parts[1:] {
+		key, value, _ := strings.Cut(param, "=")
+		p.params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+	}
+
+	return p, nil
+}
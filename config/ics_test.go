@@ -0,0 +1,233 @@
+/* SPDX-License-Identifier: MIT */
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const icsFixture = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"SUMMARY:Working hours\r\n" +
+	"DTSTART;TZID=Europe/Berlin:20260105T090000\r\n" +
+	"DTEND;TZID=Europe/Berlin:20260105T170000\r\n" +
+	"RRULE:FREQ=WEEKLY;BYDAY=MO,TU,WE,TH\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"SUMMARY:Working hours\r\n" +
+	"DTSTART;TZID=Europe/Berlin:20260109T090000\r\n" +
+	"DURATION:PT4H\r\n" +
+	"RRULE:FREQ=WEEKLY\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"SUMMARY:Christmas\r\n" +
+	"  vacation\r\n" +
+	"DTSTART;VALUE=DATE:20261224\r\n" +
+	"DTEND;VALUE=DATE:20261227\r\n" +
+	"X-MICROSOFT-CDO-BUSYSTATUS:OOF\r\n" +
+	"BEGIN:VALARM\r\n" +
+	"TRIGGER:-PT15M\r\n" +
+	"END:VALARM\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"SUMMARY:Doctor\r\n" +
+	"DTSTART:20261102T120000Z\r\n" +
+	"DTEND:20261102T140000Z\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"SUMMARY:Conference\r\n" +
+	"DTSTART;TZID=Europe/Berlin:20261110T140000\r\n" +
+	"DTEND;TZID=Europe/Berlin:20261112T120000\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"SUMMARY:Lunch maybe\r\n" +
+	"DTSTART;TZID=Europe/Berlin:20261103T120000\r\n" +
+	"DTEND;TZID=Europe/Berlin:20261103T130000\r\n" +
+	"TRANSP:TRANSPARENT\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"SUMMARY:Cancelled\r\n" +
+	"DTSTART;TZID=Europe/Berlin:20261104T120000\r\n" +
+	"DTEND;TZID=Europe/Berlin:20261104T130000\r\n" +
+	"STATUS:CANCELLED\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestParseIcs(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	c, err := ParseIcs(strings.NewReader(icsFixture), berlin)
+	require.NoError(t, err)
+
+	assert.Equal(t, "09:00/17:00", c.RawWorkingHours()[time.Monday])
+	assert.Equal(t, "09:00/17:00", c.RawWorkingHours()[time.Thursday])
+	assert.Equal(t, "09:00/13:00", c.RawWorkingHours()[time.Friday])
+	assert.NotContains(t, c.WorkingHours, time.Saturday)
+
+	assert.Equal(t, []DateRange{
+		{From: Date{2026, time.November, 11}, To: Date{2026, time.November, 11}},
+		{From: Date{2026, time.December, 24}, To: Date{2026, time.December, 26}},
+	}, c.Off)
+
+	//Doctor is in UTC, conference spans three days
+	assert.Equal(t, "2026-11-02 13:00/15:00, 2026-11-10 14:00/00:00, 2026-11-12 00:00/12:00", RawDateFrames(c.Blocks))
+}
+
+func TestParseIcsRecurring(t *testing.T) {
+	ics := "BEGIN:VCALENDAR\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"SUMMARY:Team standup!\r\n" +
+		"DTSTART:20261102T090000\r\n" +
+		"DTEND:20261102T093000\r\n" +
+		"RRULE:FREQ=WEEKLY;BYDAY=MO,WE\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"SUMMARY:Team standup\r\n" +
+		"DTSTART;VALUE=DATE:20261106\r\n" +
+		"RRULE:FREQ=WEEKLY;INTERVAL=2\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"SUMMARY:Odd one\r\n" +
+		"DTSTART:20261102T090000\r\n" +
+		"RRULE:FREQ=MONTHLY;BYSETPOS=-1;BYDAY=MO\r\n" +
+		"END:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+
+	c, err := ParseIcs(strings.NewReader(ics), time.UTC)
+	require.NoError(t, err)
+
+	require.Len(t, c.Rules, 2)
+	assert.Equal(t, "ics-team-standup", c.Rules[0].Name)
+	assert.Equal(t, "FREQ=WEEKLY;BYDAY=MO,WE;DTSTART=20261102 block 09:00/09:30", c.Rules[0].Raw())
+	assert.Equal(t, "ics-team-standup-2", c.Rules[1].Name)
+	assert.Equal(t, "FREQ=WEEKLY;INTERVAL=2;DTSTART=20261106 off", c.Rules[1].Raw())
+	assert.Equal(t, []string{"Odd one"}, c.Skipped)
+}
+
+func TestParseIcsExceptions(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	ics := "BEGIN:VCALENDAR\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"SUMMARY:Working hours\r\n" +
+		"DESCRIPTION:" + strings.Repeat("x", 100*1024) + "\r\n" +
+		"DTSTART;TZID=America/New_York:20261102T200000\r\n" +
+		"DTEND;TZID=America/New_York:20261102T220000\r\n" +
+		"RRULE:FREQ=WEEKLY;BYDAY=MO,TU\r\n" +
+		"EXDATE;TZID=America/New_York:20261109T200000\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"UID:standup\r\n" +
+		"SUMMARY:Standup\r\n" +
+		"DTSTART;TZID=Europe/Berlin:20261102T090000\r\n" +
+		"DTEND;TZID=Europe/Berlin:20261102T093000\r\n" +
+		"RRULE:FREQ=WEEKLY;BYDAY=MO\r\n" +
+		"EXDATE;TZID=Europe/Berlin:20261109T090000,20261116T090000\r\n" +
+		"EXDATE;TZID=Europe/Berlin:20261123T090000\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"UID:standup\r\n" +
+		"SUMMARY:Standup\r\n" +
+		"RECURRENCE-ID;TZID=Europe/Berlin:20261130T090000\r\n" +
+		"DTSTART;TZID=Europe/Berlin:20261130T090000\r\n" +
+		"DTEND;TZID=Europe/Berlin:20261130T093000\r\n" +
+		"STATUS:CANCELLED\r\n" +
+		"END:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+
+	c, err := ParseIcs(strings.NewReader(ics), berlin)
+	require.NoError(t, err)
+
+	//20:00 in New York is 02:00 of the next day in Berlin
+	assert.NotContains(t, c.WorkingHours, time.Monday)
+	assert.Equal(t, "02:00/04:00", c.RawWorkingHours()[time.Tuesday])
+	assert.Equal(t, "02:00/04:00", c.RawWorkingHours()[time.Wednesday])
+	assert.Equal(t, "2026-11-10 02:00/04:00", RawDateFrames(c.Blocks), "skipped working hours are blocks")
+
+	require.Len(t, c.Rules, 1)
+	assert.Equal(t, "FREQ=WEEKLY;BYDAY=MO;DTSTART=20261102;EXDATE=20261130,20261109,20261116,20261123 block 09:00/09:30", c.Rules[0].Raw())
+	assert.True(t, c.Rules[0].RRule.Matches(Date{2026, time.November, 2}))
+	assert.False(t, c.Rules[0].RRule.Matches(Date{2026, time.November, 9}))
+	assert.False(t, c.Rules[0].RRule.Matches(Date{2026, time.November, 30}))
+	assert.True(t, c.Rules[0].RRule.Matches(Date{2026, time.December, 7}))
+}
+
+func TestParseIcsDuration(t *testing.T) {
+	d, err := parseIcsDuration("P1DT2H30M")
+	assert.NoError(t, err)
+	assert.Equal(t, 26*time.Hour+30*time.Minute, d)
+
+	d, err = parseIcsDuration("P1W")
+	assert.NoError(t, err)
+	assert.Equal(t, 7*24*time.Hour, d)
+
+	_, err = parseIcsDuration("1H")
+	assert.Error(t, err)
+}
+
+func TestScheduleWithIcsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "calendar.ics")
+	require.NoError(t, os.WriteFile(path, []byte(icsFixture), 0644))
+
+	raw := RawScheduleConfig{
+		Days: map[time.Weekday]string{
+			//Configured days win over the calendar
+			time.Monday: "10:00/18:00",
+		},
+		Timezone: "Europe/Berlin",
+		IcsFile:  path,
+	}
+	s, err := NewScheduleFromRaw(&raw)
+	require.NoError(t, err)
+
+	assert.Equal(t, []TimeFrame{{600, 1080}}, s.DecentTimeFrames(time.Monday))
+	assert.Equal(t, []TimeFrame{{540, 1020}}, s.DecentTimeFrames(time.Tuesday))
+	assert.Equal(t, []TimeFrame{{540, 780}}, s.DecentTimeFrames(time.Friday))
+
+	assert.True(t, s.IsOff(Date{2026, time.December, 24}))
+	assert.Equal(t, TimeFrames{{600, 779}, {900, 1080}}, s.FramesOn(Date{2026, time.November, 2}))
+	assert.Equal(t, TimeFrames{{540, 839}}, s.FramesOn(Date{2026, time.November, 10}))
+	assert.Empty(t, s.FramesOn(Date{2026, time.November, 11}))
+	assert.Equal(t, TimeFrames{{720, 1020}}, s.FramesOn(Date{2026, time.November, 12}))
+
+	raw.IcsFile = filepath.Join(t.TempDir(), "missing.ics")
+	_, err = NewScheduleFromRaw(&raw)
+	assert.ErrorContains(t, err, "icsFile")
+}
+
+func TestScheduleBlocks(t *testing.T) {
+	raw := RawScheduleConfig{
+		Days: map[time.Weekday]string{
+			time.Monday:  "09:00/17:00, 22:00/02:00",
+			time.Tuesday: "09:00/17:00",
+		},
+		Block: "2026-11-02 12:00/13:00, 23:00/01:00, 2026-11-03 09:00/10:00",
+	}
+	s, err := NewScheduleFromRaw(&raw)
+	require.NoError(t, err)
+
+	assert.Equal(t, TimeFrames{{540, 719}, {780, 1020}, {1320, 1379}, {1500, 1560}}, s.FramesOn(Date{2026, time.November, 2}))
+	assert.Equal(t, TimeFrames{{600, 1020}}, s.FramesOn(Date{2026, time.November, 3}))
+
+	layout := "2006-01-02 15:04"
+	pTime, err := time.Parse(layout, "2026-11-02 12:30")
+	require.NoError(t, err)
+	assertTime(t, pTime.Add(30*time.Minute), s.Next(pTime))
+
+	//00:30 is blocked, 01:30 is still part of Monday night frame
+	pTime, err = time.Parse(layout, "2026-11-03 00:30")
+	require.NoError(t, err)
+	assert.False(t, s.Contains(pTime))
+	pTime, err = time.Parse(layout, "2026-11-03 01:30")
+	require.NoError(t, err)
+	assert.True(t, s.Contains(pTime))
+}
@@ -0,0 +1,116 @@
+/* SPDX-License-Identifier: MIT */
+package config
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Commits needed to tell a working pattern apart from noise
+const minInferCommits = 10
+
+// A schedule proposed from the dates of existing commits
+type Inference struct {
+	Config RawScheduleConfig
+	// Commits analysed and how many of them fall within the proposal
+	Commits int
+	Covered int
+}
+
+// InferSchedule proposes the weekdays and hours holding at least the given
+// share (0 to 1) of the commits. Dates are read in their own offset, like
+// the schedule does when there is no timezone. Hours next to each other
+// become a single frame, the ones around midnight an overnight frame.
+func InferSchedule(dates []time.Time, coverage float64) (*Inference, error) {
+	if len(dates) < minInferCommits {
+		return nil, fmt.Errorf("at least %d commits are needed to infer a schedule, found %d", minInferCommits, len(dates))
+	}
+	if coverage <= 0 || coverage > 1 {
+		return nil, fmt.Errorf("coverage should be between 0 and 100%%, got %.0f%%", coverage*100)
+	}
+
+	perDay := map[time.Weekday]int{}
+	for _, date := range dates {
+		perDay[date.Weekday()]++
+	}
+	days := mostCommon(perDay, coverage)
+	inDays := map[time.Weekday]bool{}
+	for _, d := range days {
+		inDays[d] = true
+	}
+
+	perHour := map[int]int{}
+	for _, date := range dates {
+		if inDays[date.Weekday()] {
+			perHour[date.Hour()]++
+		}
+	}
+	hours := mostCommon(perHour, coverage)
+	inHours := map[int]bool{}
+	for _, h := range hours {
+		inHours[h] = true
+	}
+
+	inference := &Inference{
+		Config:  RawScheduleConfig{Days: map[time.Weekday]string{}},
+		Commits: len(dates),
+	}
+	frames := hourFrames(inHours).Raw()
+	for _, d := range days {
+		inference.Config.Days[d] = frames
+	}
+	for _, date := range dates {
+		if inDays[date.Weekday()] && inHours[date.Hour()] {
+			inference.Covered++
+		}
+	}
+	return inference, nil
+}
+
+// The keys with the most commits until they hold the given share of them
+func mostCommon[K time.Weekday | int](counts map[K]int, coverage float64) []K {
+	keys := []K{}
+	total := 0
+	for key, count := range counts {
+		keys = append(keys, key)
+		total += count
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+
+	taken := 0
+	for k, key := range keys {
+		taken += counts[key]
+		if float64(taken) >= coverage*float64(total) {
+			return keys[:k+1]
+		}
+	}
+	return keys
+}
+
+// Consecutive hours are joined in frames, the ones reaching midnight
+// continue with the first hours of the day
+func hourFrames(hours map[int]bool) TimeFrames {
+	if len(hours) == 24 {
+		return TimeFrames{{StartMinute: 0, EndMinute: dayMinutes - 1}}
+	}
+
+	frames := TimeFrames{}
+	for h := 0; h < 24; h++ {
+		//Frames start at an hour without the previous one
+		if !hours[h] || hours[(h+23)%24] {
+			continue
+		}
+		end := h
+		for hours[(end+1)%24] {
+			end++
+		}
+		frames = append(frames, TimeFrame{StartMinute: h * 60, EndMinute: (end + 1) * 60})
+	}
+	return frames
+}
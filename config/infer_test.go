@@ -0,0 +1,59 @@
+/* SPDX-License-Identifier: MIT */
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInferSchedule(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	dates := []time.Time{}
+	//Four weeks of commits from Monday to Thursday, mornings and afternoons
+	for week := 0; week < 4; week++ {
+		for day := 0; day < 4; day++ {
+			for _, hour := range []int{9, 10, 11, 14, 15, 16} {
+				dates = append(dates, time.Date(2026, 11, 2+week*7+day, hour, 30, 0, 0, berlin))
+			}
+		}
+	}
+	//A couple of late nights and a Saturday
+	dates = append(dates,
+		time.Date(2026, 11, 3, 23, 10, 0, 0, berlin),
+		time.Date(2026, 11, 14, 12, 0, 0, 0, berlin),
+	)
+
+	inference, err := InferSchedule(dates, 0.9)
+	require.NoError(t, err)
+	assert.Equal(t, map[time.Weekday]string{
+		time.Monday:    "09:00/12:00, 14:00/17:00",
+		time.Tuesday:   "09:00/12:00, 14:00/17:00",
+		time.Wednesday: "09:00/12:00, 14:00/17:00",
+		time.Thursday:  "09:00/12:00, 14:00/17:00",
+	}, inference.Config.Days)
+	assert.Equal(t, len(dates), inference.Commits)
+	assert.Equal(t, 96, inference.Covered)
+
+	_, err = NewScheduleFromRaw(&inference.Config)
+	assert.NoError(t, err)
+
+	_, err = InferSchedule(dates[:3], 0.9)
+	assert.ErrorContains(t, err, "at least 10 commits")
+	_, err = InferSchedule(dates, 1.5)
+	assert.ErrorContains(t, err, "coverage")
+}
+
+func TestHourFrames(t *testing.T) {
+	assert.Equal(t, "09:00/10:00, 22:00/02:00", hourFrames(map[int]bool{22: true, 23: true, 0: true, 1: true, 9: true}).Raw())
+
+	all := map[int]bool{}
+	for h := 0; h < 24; h++ {
+		all[h] = true
+	}
+	assert.Equal(t, "00:00/23:59", hourFrames(all).Raw())
+}
@@ -0,0 +1,172 @@
+/* SPDX-License-Identifier: MIT */
+package config
+
+import (
+	"sort"
+	"time"
+)
+
+// A span of decent time, Start is included and End is not
+type Interval struct {
+	Start time.Time
+	End   time.Time
+}
+
+func (i Interval) Contains(t time.Time) bool {
+	return !t.Before(i.Start) && t.Before(i.End)
+}
+
+func (i Interval) String() string {
+	return i.Start.Format("2006-01-02 15:04") + " - " + i.End.Format("2006-01-02 15:04")
+}
+
+// How far Next and Prev look for a decent interval
+const maxDaysAhead = 2 * 366
+
+// Next and Prev look for intervals a week at a time
+const searchDays = 7
+
+// Contains tells if the given time is within a decent frame, any time the
+// team policy allows is decent when the schedule is disabled
+func (s *Schedule) Contains(t time.Time) bool {
+	if s.Policy != nil && !s.Policy.Contains(t) {
+		return false
+	}
+	if s.Disabled {
+		return true
+	}
+	t = s.In(t)
+	return s.containsSecond(DateOf(t), DaySecond(t))
+}
+
+// Next returns the given time when it is decent or the start of the next
+// decent interval otherwise. When there are no decent frames at all the
+// given time is returned.
+func (s *Schedule) Next(t time.Time) time.Time {
+	t = s.In(t)
+	for nDay := 0; nDay < maxDaysAhead; nDay += searchDays {
+		from := t.AddDate(0, 0, nDay)
+		windows := s.Windows(from, from.AddDate(0, 0, searchDays))
+		if len(windows) > 0 {
+			return windows[0].Start
+		}
+	}
+	return t
+}
+
+// Prev returns the given time when it is decent or the last decent minute
+// of the previous interval otherwise. When there are no decent frames at all
+// the given time is returned.
+func (s *Schedule) Prev(t time.Time) time.Time {
+	t = s.In(t)
+	if s.Contains(t) {
+		return t
+	}
+	for nDay := 0; nDay < maxDaysAhead; nDay += searchDays {
+		to := t.AddDate(0, 0, -nDay)
+		windows := s.Windows(to.AddDate(0, 0, -searchDays), to)
+		if len(windows) > 0 {
+			return windows[len(windows)-1].End.Add(-time.Minute)
+		}
+	}
+	return t
+}
+
+// Windows returns the decent intervals between two times, sorted, merged
+// when they touch and clipped to from and to
+func (s *Schedule) Windows(from time.Time, to time.Time) []Interval {
+	from = s.In(from)
+	to = s.In(to)
+	if !from.Before(to) {
+		return nil
+	}
+	if s.Disabled && s.Policy != nil {
+		return s.Policy.Windows(from, to)
+	}
+	if s.Disabled {
+		return []Interval{{Start: from, End: to}}
+	}
+
+	intervals := []Interval{}
+	//Overnight frames of the day before can reach the first day
+	for date := DateOf(from).AddDays(-1); !DateOf(to).Before(date); date = date.AddDays(1) {
+		for _, frame := range s.FramesOn(date) {
+			intervals = append(intervals, Interval{
+				Start: date.wallClock(frame.StartMinute, from.Location(), false),
+				End:   date.wallClock(frame.EndMinute+1, from.Location(), true),
+			})
+		}
+	}
+	sort.Slice(intervals, func(i, j int) bool {
+		return intervals[i].Start.Before(intervals[j].Start)
+	})
+
+	windows := []Interval{}
+	for _, i := range intervals {
+		//Frames can vanish in the hour skipped by a DST change
+		if !i.Start.Before(i.End) || !i.End.After(from) || !i.Start.Before(to) {
+			continue
+		}
+		if i.Start.Before(from) {
+			i.Start = from
+		}
+		if i.End.After(to) {
+			i.End = to
+		}
+
+		last := len(windows) - 1
+		if last >= 0 && !i.Start.After(windows[last].End) {
+			if i.End.After(windows[last].End) {
+				windows[last].End = i.End
+			}
+			continue
+		}
+		windows = append(windows, i)
+	}
+
+	if s.Policy != nil {
+		return intersectIntervals(windows, s.Policy.Windows(from, to), from.Location())
+	}
+	return windows
+}
+
+// The time at the given minute of the date, minutes past the day go on to
+// the following days. Wall clocks skipped by a DST change are moved to the
+// change itself and the ones repeated by it are the first occurrence, or the
+// last one when latest is set.
+func (d Date) wallClock(minute int, loc *time.Location, latest bool) time.Time {
+	t := time.Date(d.Year, d.Month, d.Day, 0, minute, 0, 0, loc)
+	start, end := t.ZoneBounds()
+
+	//Normalized into a different wall clock, it doesn't exist. Depending on
+	//the zone it is moved before or after the change.
+	wall := time.Date(d.Year, d.Month, d.Day, 0, minute, 0, 0, time.UTC)
+	tWall := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, time.UTC)
+	if tWall.Before(wall) {
+		return end
+	}
+	if tWall.After(wall) {
+		return start
+	}
+
+	_, offset := t.Zone()
+	if latest && !end.IsZero() {
+		_, nextOffset := end.Zone()
+		later := t.Add(time.Duration(offset-nextOffset) * time.Second)
+		if later.After(t) && sameWallClock(t, later) {
+			return later
+		}
+	}
+	if !latest && !start.IsZero() {
+		_, prevOffset := start.Add(-time.Second).Zone()
+		earlier := t.Add(time.Duration(offset-prevOffset) * time.Second)
+		if earlier.Before(t) && sameWallClock(t, earlier) {
+			return earlier
+		}
+	}
+	return t
+}
+
+func sameWallClock(a time.Time, b time.Time) bool {
+	return a.Format(time.DateTime) == b.Format(time.DateTime)
+}
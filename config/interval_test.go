@@ -0,0 +1,139 @@
+/* SPDX-License-Identifier: MIT */
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func assertTime(t *testing.T, expected time.Time, actual time.Time) {
+	t.Helper()
+	assert.True(t, expected.Equal(actual), "expected %s, got %s", expected, actual)
+}
+
+func TestWindows(t *testing.T) {
+	raw := RawScheduleConfig{
+		Days: map[time.Weekday]string{
+			time.Monday:  "09:00/12:00, 12:01/17:00",
+			time.Friday:  "22:00/02:00",
+			time.Tuesday: "09:00/17:00",
+		},
+		Off: "2026-11-03",
+	}
+	s, err := NewScheduleFromRaw(&raw)
+	require.NoError(t, err)
+
+	at := func(day int, hour int, min int) time.Time {
+		return time.Date(2026, time.November, day, hour, min, 0, 0, time.UTC)
+	}
+
+	//Touching frames are merged, Tuesday is off, Friday night reaches Saturday
+	windows := s.Windows(at(2, 0, 0), at(9, 0, 0))
+	require.Len(t, windows, 2)
+	assertTime(t, at(2, 9, 0), windows[0].Start)
+	assertTime(t, at(2, 17, 1), windows[0].End)
+	assertTime(t, at(6, 22, 0), windows[1].Start)
+	assertTime(t, at(7, 2, 1), windows[1].End)
+
+	//Windows are clipped
+	windows = s.Windows(at(7, 1, 0), at(9, 10, 0))
+	require.Len(t, windows, 2)
+	assertTime(t, at(7, 1, 0), windows[0].Start)
+	assertTime(t, at(9, 10, 0), windows[1].End)
+	assert.True(t, windows[1].Contains(at(9, 9, 30)))
+	assert.False(t, windows[1].Contains(at(9, 10, 0)))
+
+	assert.Empty(t, s.Windows(at(9, 10, 0), at(9, 9, 0)))
+}
+
+func TestNextPrev(t *testing.T) {
+	raw := RawScheduleConfig{
+		Days: map[time.Weekday]string{
+			time.Monday: "09:00/17:00",
+			time.Friday: "22:00/02:00",
+		},
+	}
+	s, err := NewScheduleFromRaw(&raw)
+	require.NoError(t, err)
+
+	at := func(day int, hour int, min int) time.Time {
+		return time.Date(2026, time.November, day, hour, min, 0, 0, time.UTC)
+	}
+
+	assertTime(t, at(2, 12, 0), s.Next(at(2, 12, 0)))
+	assertTime(t, at(2, 12, 0), s.Prev(at(2, 12, 0)))
+	assertTime(t, at(2, 17, 0), s.Next(at(2, 17, 0)))
+
+	assertTime(t, at(6, 22, 0), s.Next(at(2, 17, 1)))
+	assertTime(t, at(2, 17, 0), s.Prev(at(2, 17, 1)))
+
+	assertTime(t, at(9, 9, 0), s.Next(at(7, 2, 1)))
+	assertTime(t, at(7, 2, 0), s.Prev(at(7, 3, 0)))
+	assertTime(t, at(7, 2, 0), s.Prev(at(9, 8, 59)))
+
+	//Without frames there is nowhere to go
+	empty, err := NewScheduleFromRaw(&RawScheduleConfig{})
+	require.NoError(t, err)
+	assertTime(t, at(2, 12, 0), empty.Next(at(2, 12, 0)))
+	assertTime(t, at(2, 12, 0), empty.Prev(at(2, 12, 0)))
+}
+
+func TestWindowsDST(t *testing.T) {
+	transitions := []struct {
+		name    string
+		zone    string
+		sunday  Date
+		windows []string
+	}{
+		//Spring forward skips 02:00-03:00, the second frame vanishes
+		{"Berlin spring", "Europe/Berlin", Date{2026, time.March, 29}, []string{
+			"00:30-01:00", "07:00-08:01",
+		}},
+		//Fall back repeats 02:00-03:00, frames cover both times
+		{"Berlin autumn", "Europe/Berlin", Date{2026, time.October, 25}, []string{
+			"23:30-01:30", "08:00-09:01",
+		}},
+		{"New York spring", "America/New_York", Date{2026, time.March, 8}, []string{
+			"06:30-07:00", "13:00-14:01",
+		}},
+		{"New York autumn", "America/New_York", Date{2026, time.November, 1}, []string{
+			"05:30-07:30", "14:00-15:01",
+		}},
+	}
+
+	for _, tc := range transitions {
+		loc, err := time.LoadLocation(tc.zone)
+		require.NoError(t, err)
+
+		//Without Timezone the frames are read in the zone of the dates, like
+		//the local one of the commits
+		for _, timezone := range []string{tc.zone, ""} {
+			t.Run(tc.name+" "+timezone, func(t *testing.T) {
+				s, err := NewScheduleFromRaw(&RawScheduleConfig{
+					Days:     map[time.Weekday]string{time.Sunday: "01:30/02:29, 02:10/02:20, 09:00/10:00"},
+					Timezone: timezone,
+				})
+				require.NoError(t, err)
+
+				windows := []string{}
+				for _, w := range s.Windows(tc.sunday.Time(loc), tc.sunday.AddDays(1).Time(loc)) {
+					windows = append(windows, w.Start.UTC().Format("15:04")+"-"+w.End.UTC().Format("15:04"))
+					assert.True(t, s.Contains(w.Start))
+					assert.True(t, s.Contains(w.End.Add(-time.Minute)))
+				}
+				assert.Equal(t, tc.windows, windows)
+
+				//The morning frame is at 09:00 whatever the offset of the day is
+				next := s.Next(tc.sunday.Time(loc).Add(4 * time.Hour))
+				assert.Equal(t, "09:00", next.Format("15:04"))
+
+				//From the day before, the first frame keeps its wall clock
+				next = s.Next(tc.sunday.AddDays(-1).Time(loc).Add(23 * time.Hour))
+				assert.Equal(t, "01:30", next.Format("15:04"))
+			})
+		}
+	}
+}
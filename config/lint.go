@@ -0,0 +1,247 @@
+/* SPDX-License-Identifier: MIT */
+package config
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// A problem found in a [decent] key
+type LintError struct {
+	Key string
+	Msg string
+}
+
+func (e LintError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Key, e.Msg)
+}
+
+// Lint checks the options of the [decent] section, it reports unknown keys,
+// invalid values and frames that overlap, are duplicated or touch
+func Lint(options map[string]string) error {
+	errs := []error{}
+	rawC := RawScheduleConfig{Days: map[time.Weekday]string{}}
+	for _, key := range sortKeysByDays(options) {
+		err := rawC.SetValue(key, options[key])
+		switch {
+		case err == nil:
+		case !isKnownKey(key):
+			errs = append(errs, unknownKey(key))
+		default:
+			errs = append(errs, err)
+		}
+	}
+
+	s, err := NewScheduleFromRaw(&rawC)
+	for _, err := range flattenErrors(err) {
+		//Frames of the weekdays are already reported by SetValue
+		if !errors.As(err, &ParseDayError{}) {
+			errs = append(errs, err)
+		}
+	}
+
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		frames := []lintFrame{}
+		for _, frame := range s.Days[d].DecentFrames {
+			frames = append(frames, lintFrame{frame: frame, name: frame.Raw()})
+		}
+		prev := strings.ToLower(((d + 6) % 7).String())
+		for _, frame := range s.Days[d].SpilloverFrames {
+			frames = append(frames, lintFrame{frame: frame, name: fmt.Sprintf("%s (from %s)", frame.Raw(), prev)})
+		}
+		errs = append(errs, lintFrames(strings.ToLower(d.String()), frames)...)
+	}
+
+	for _, date := range sortedDates(s.Overrides) {
+		errs = append(errs, lintFrames("override "+date.String(), namedFrames(s.Overrides[date]))...)
+	}
+	for _, rule := range s.Rules {
+		errs = append(errs, lintFrames("rule."+rule.Name, namedFrames(rule.Frames))...)
+	}
+
+	return errors.Join(errs...)
+}
+
+// CheckOption validates a single [decent] key and its value before it is set
+func CheckOption(key string, value string) error {
+	key = strings.ToLower(key)
+	if !isKnownKey(key) {
+		return unknownKey(key)
+	}
+
+	rawC := RawScheduleConfig{Days: map[time.Weekday]string{}}
+	if err := rawC.SetValue(key, value); err != nil {
+		return err
+	}
+	_, err := NewScheduleFromRaw(&rawC)
+	return err
+}
+
+func unknownKey(key string) LintError {
+	msg := "unknown key"
+	if suggestion := suggestKey(key); suggestion != "" {
+		msg = fmt.Sprintf("unknown key, did you mean %s?", suggestion)
+	}
+	return LintError{Key: key, Msg: msg}
+}
+
+// Joined errors are listed one by one
+func flattenErrors(err error) []error {
+	if err == nil {
+		return nil
+	}
+	unwrap, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return []error{err}
+	}
+	errs := []error{}
+	for _, err := range unwrap.Unwrap() {
+		errs = append(errs, flattenErrors(err)...)
+	}
+	return errs
+}
+
+type lintFrame struct {
+	frame TimeFrame
+	name  string
+}
+
+func namedFrames(frames TimeFrames) []lintFrame {
+	named := make([]lintFrame, len(frames))
+	for k, frame := range frames {
+		named[k] = lintFrame{frame: frame, name: frame.Raw()}
+	}
+	return named
+}
+
+func lintFrames(key string, frames []lintFrame) []error {
+	sort.SliceStable(frames, func(i, j int) bool {
+		if frames[i].frame.StartMinute != frames[j].frame.StartMinute {
+			return frames[i].frame.StartMinute < frames[j].frame.StartMinute
+		}
+		return frames[i].frame.EndMinute < frames[j].frame.EndMinute
+	})
+
+	errs := []error{}
+	if len(frames) == 0 {
+		return errs
+	}
+	//Frames are checked against the one ending the latest so far, it might
+	//hold several of them
+	latest := frames[0]
+	for k := 1; k < len(frames); k++ {
+		cur := frames[k]
+		switch {
+		case frames[k-1].frame == cur.frame:
+			errs = append(errs, LintError{Key: key, Msg: fmt.Sprintf("frame %s is given twice", cur.name)})
+		//Like Contains, frames include their end minute
+		case cur.frame.StartMinute == latest.frame.EndMinute:
+			minute := cur.frame.StartMinute % dayMinutes
+			errs = append(errs, LintError{Key: key, Msg: fmt.Sprintf("frames %s and %s share %02d:%02d, they can be a single frame", latest.name, cur.name, minute/60, minute%60)})
+		case cur.frame.StartMinute < latest.frame.EndMinute:
+			errs = append(errs, LintError{Key: key, Msg: fmt.Sprintf("frames %s and %s overlap", latest.name, cur.name)})
+		case cur.frame.StartMinute == latest.frame.EndMinute+1:
+			errs = append(errs, LintError{Key: key, Msg: fmt.Sprintf("frames %s and %s touch, they can be a single frame", latest.name, cur.name)})
+		}
+		if cur.frame.EndMinute > latest.frame.EndMinute {
+			latest = cur
+		}
+	}
+	return errs
+}
+
+func sortedDates(dateFrames map[Date]TimeFrames) []Date {
+	dates := make([]Date, 0, len(dateFrames))
+	for date := range dateFrames {
+		dates = append(dates, date)
+	}
+	sort.Slice(dates, func(i, j int) bool {
+		return dates[i].Before(dates[j])
+	})
+	return dates
+}
+
+func isKnownKey(key string) bool {
+	if _, inner, found := splitContextKey(key); found {
+		return isKnownKey(inner)
+	}
+	if _, found := lookupKey(key); found {
+		return true
+	}
+	return daysOf(key) != nil || strings.HasPrefix(key, "rule.")
+}
+
+// suggestKey returns the closest known key, ranges like mon-fir are
+// suggested per day
+func suggestKey(key string) string {
+	if context, inner, found := splitContextKey(key); found {
+		if suggestion := suggestKey(inner); suggestion != "" {
+			return context + "." + suggestion
+		}
+		return ""
+	}
+	if from, to, found := strings.Cut(key, "-"); found {
+		from, to = suggestDay(from), suggestDay(to)
+		if from == "" || to == "" {
+			return ""
+		}
+		return from + "-" + to
+	}
+
+	candidates := []string{"weekdays", "weekend"}
+	for _, known := range Keys {
+		candidates = append(candidates, strings.ToLower(known.Name))
+	}
+	for name := range dayNames {
+		candidates = append(candidates, name)
+	}
+	return closest(key, candidates)
+}
+
+func suggestDay(day string) string {
+	if _, exists := dayNames[day]; exists {
+		return day
+	}
+	candidates := []string{}
+	for name := range dayNames {
+		candidates = append(candidates, name)
+	}
+	return closest(day, candidates)
+}
+
+// The candidate with the fewest edits, as long as it is a typo and not a
+// different word
+func closest(word string, candidates []string) string {
+	sort.Strings(candidates)
+	best := ""
+	bestDistance := len(word)/3 + 1
+	for _, candidate := range candidates {
+		if d := levenshtein(word, candidate); d <= bestDistance && (best == "" || d < levenshtein(word, best)) {
+			best = candidate
+		}
+	}
+	return best
+}
+
+func levenshtein(a string, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,87 @@
+/* SPDX-License-Identifier: MIT */
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLint(t *testing.T) {
+	err := Lint(map[string]string{
+		"weekdays":  "09:00/13:00, 14:00/17:00",
+		"timezone":  "Europe/Berlin",
+		"rule.nine": "FREQ=WEEKLY;INTERVAL=2;BYDAY=FR;DTSTART=20260109 off",
+	})
+	assert.NoError(t, err)
+
+	err = Lint(map[string]string{
+		"wedensday": "9-17",
+		"mon-fir":   "9-17",
+		"colour":    "blue",
+		"tuesday":   "9-13, 13:01-17",
+		"wednesday": "09:00-12:00,12:00-14:00",
+		"sunday":    "9:00-17:00, 10:00-11:00, 12:00-13:00",
+		"thursday":  "9-12, 11-17",
+		"friday":    "9-12, 9-12, 22-2",
+		"saturday":  "1-3",
+		"monday":    "9-25",
+		"timezone":  "Europe/Nowhere",
+		"override":  "2026-11-02 9-12, 12:01-13",
+	})
+	assert.ErrorContains(t, err, "wedensday: unknown key, did you mean wednesday?")
+	assert.ErrorContains(t, err, "wednesday: frames 09:00/12:00 and 12:00/14:00 share 12:00, they can be a single frame")
+	assert.ErrorContains(t, err, "mon-fir: unknown key, did you mean mon-fri?")
+	assert.ErrorContains(t, err, "colour: unknown key\n")
+	assert.ErrorContains(t, err, "tuesday: frames 09:00/13:00 and 13:01/17:00 touch")
+	assert.ErrorContains(t, err, "thursday: frames 09:00/12:00 and 11:00/17:00 overlap")
+	assert.ErrorContains(t, err, "sunday: frames 09:00/17:00 and 10:00/11:00 overlap")
+	assert.ErrorContains(t, err, "sunday: frames 09:00/17:00 and 12:00/13:00 overlap")
+	assert.ErrorContains(t, err, "friday: frame 09:00/12:00 is given twice")
+	assert.ErrorContains(t, err, "saturday: frames 00:00/02:00 (from friday) and 01:00/03:00 overlap")
+	assert.ErrorContains(t, err, `monday: frame "9-25": hour out of range`)
+	assert.ErrorContains(t, err, "invalid timezone")
+	assert.ErrorContains(t, err, "override 2026-11-02: frames 09:00/12:00 and 12:01/13:00 touch")
+}
+
+func TestGetGitRawConfigError(t *testing.T) {
+	options := map[string]string{
+		"monday":    "09:00/17:00",
+		"wedensday": "09:00/17:00",
+	}
+	rawC, err := GetGitRawConfig(&options)
+	assert.ErrorContains(t, err, "wedensday")
+	assert.Equal(t, "09:00/17:00", rawC.Days[time.Monday])
+}
+
+func TestSuggestKey(t *testing.T) {
+	assert.Equal(t, "wednesday", suggestKey("wendesday"))
+	assert.Equal(t, "timezone", suggestKey("timezon"))
+	assert.Equal(t, "holidays", suggestKey("holiday"))
+	assert.Equal(t, "fri", suggestKey("fir"))
+	assert.Equal(t, "sat-sun", suggestKey("sta-sun"))
+	assert.Equal(t, "", suggestKey("colour"))
+}
+
+func TestCheckOption(t *testing.T) {
+	assert.NoError(t, CheckOption("Saturday", "10-12"))
+	assert.NoError(t, CheckOption("Off", "2026-12-24..2026-12-26"))
+	assert.NoError(t, CheckOption("rule.standup", "FREQ=WEEKLY;BYDAY=MO block 9-10"))
+	assert.EqualError(t, CheckOption("Wendesday", "9-17"), "wendesday: unknown key, did you mean wednesday?")
+	assert.ErrorContains(t, CheckOption("Monday", "9-25"), "hour out of range")
+	assert.ErrorContains(t, CheckOption("Timezone", "Mars/Olympus"), "invalid timezone")
+	assert.NoError(t, CheckOption("Strategy", "Preserve-Gaps"))
+	assert.NoError(t, CheckOption("Capacity", "6/hour"))
+	assert.NoError(t, CheckOption("MinGap", "10"))
+	assert.EqualError(t, CheckOption("Capacity", "6/day"), "capacity: expected hour or frame after the commits, given day")
+	assert.EqualError(t, CheckOption("Capacity", "many"), "capacity: expected commits per hour or frame like 6/hour, given many")
+	assert.EqualError(t, CheckOption("MinGap", "10m"), "mingap: expected minutes, given 10m")
+	assert.NoError(t, CheckOption("Threshold", "120"))
+	assert.NoError(t, CheckOption("Noise", "2-15 Normal"))
+	assert.NoError(t, CheckOption("Jitter", "59"))
+	assert.EqualError(t, CheckOption("Noise", "15-2"), "noise: expected a range of minutes like 1-10 and optionally uniform or normal, given 15-2")
+	assert.EqualError(t, CheckOption("Noise", "1-10 gaussian"), "noise: unknown distribution gaussian, expected uniform or normal")
+	assert.EqualError(t, CheckOption("Jitter", "60"), "jitter: expected seconds between 0 and 59, given 60")
+	assert.EqualError(t, CheckOption("Strategy", "random"), "strategy: unknown strategy random, expected one of next-frame, previous-frame, spread, preserve-gaps")
+}
@@ -0,0 +1,244 @@
+/* SPDX-License-Identifier: MIT */
+package config
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A [decent] key with its value, rules are keyed as rule.<name>
+type Option struct {
+	Key   string
+	Value string
+}
+
+// Monday first, like a working week
+var weekdayOrder = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday, time.Sunday}
+
+// Raw returns the configuration of the schedule, what comes from the calendar
+// is left out since IcsFile brings it back
+func (s *Schedule) Raw() RawScheduleConfig {
+	rawC := RawScheduleConfig{Days: map[time.Weekday]string{}}
+	for d, day := range s.Days {
+		if len(day.DecentFrames) > 0 && day.origin == "" {
+			rawC.Days[time.Weekday(d)] = day.DecentFrames.Raw()
+		}
+	}
+	rawC.Preset = s.Preset
+	if s.Location != nil {
+		rawC.Timezone = s.Location.String()
+	}
+	if s.Holidays != nil {
+		rawC.Holidays = strings.Join(s.Holidays.Regions, ", ")
+	}
+	rawC.Override = RawDateFrames(s.Overrides)
+	rawC.IcsFile = s.IcsFile
+	if s.Disabled {
+		rawC.Enabled = "false"
+	}
+	rawC.Strategy = s.Strategy
+	if s.Capacity != nil {
+		rawC.Capacity = s.Capacity.Raw()
+	}
+	rawC.MinGap = rawMinutes(s.MinGap)
+	rawC.Threshold = rawMinutes(s.Threshold)
+	if s.Noise != nil {
+		rawC.Noise = s.Noise.Raw()
+	}
+	if s.Jitter > 0 {
+		rawC.Jitter = strconv.Itoa(int(s.Jitter.Seconds()))
+	}
+	rawC.Contexts = s.contexts
+
+	off, blocks, rules := s.Off, s.Blocks, s.Rules
+	if s.calendar != nil {
+		//Days off and rules of the calendar are appended after the configured ones
+		off = off[:len(off)-len(s.calendar.Off)]
+		rules = rules[:len(rules)-len(s.calendar.Rules)]
+		blocks = withoutFrames(blocks, s.calendar.Blocks)
+	}
+	rawC.Off = RawOff(off)
+	rawC.Block = RawDateFrames(blocks)
+	for _, rule := range rules {
+		if rawC.Rules == nil {
+			rawC.Rules = map[string]string{}
+		}
+		rawC.Rules[rule.Name] = rule.Raw()
+	}
+
+	return rawC
+}
+
+// The frames of each date without the ones given in remove
+func withoutFrames(dateFrames map[Date]TimeFrames, remove map[Date]TimeFrames) map[Date]TimeFrames {
+	result := map[Date]TimeFrames{}
+	for date, frames := range dateFrames {
+		kept := TimeFrames{}
+		removed := map[TimeFrame]int{}
+		for _, frame := range remove[date] {
+			removed[frame]++
+		}
+		for _, frame := range frames {
+			if removed[frame] > 0 {
+				removed[frame]--
+				continue
+			}
+			kept = append(kept, frame)
+		}
+		if len(kept) > 0 {
+			result[date] = kept
+		}
+	}
+	return result
+}
+
+// Options returns the keys in the order they are written: the preset, the
+// days from Monday, the exceptions, the rules sorted by name and the remote
+// and branch subsections
+func (config *RawScheduleConfig) Options() []Option {
+	options := []Option{}
+	add := func(key Key) {
+		if value := *key.field(config); value != "" {
+			options = append(options, Option{Key: key.Name, Value: value})
+		}
+	}
+
+	add(Keys[0])
+	for _, d := range weekdayOrder {
+		if config.Days[d] != "" {
+			options = append(options, Option{Key: d.String(), Value: config.Days[d]})
+		}
+	}
+	for _, key := range Keys[1:] {
+		add(key)
+	}
+
+	names := make([]string, 0, len(config.Rules))
+	for name := range config.Rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		options = append(options, Option{Key: "rule." + name, Value: config.Rules[name]})
+	}
+
+	contexts := make([]string, 0, len(config.Contexts))
+	for context := range config.Contexts {
+		contexts = append(contexts, context)
+	}
+	sort.Strings(contexts)
+	for _, context := range contexts {
+		keys := make([]string, 0, len(config.Contexts[context]))
+		for key := range config.Contexts[context] {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			options = append(options, Option{Key: context + "." + key, Value: config.Contexts[context][key]})
+		}
+	}
+
+	return options
+}
+
+// PlainText returns the configuration in the git config format, it can be
+// read back with NewScheduleFromPlainText
+func (config *RawScheduleConfig) PlainText() string {
+	return config.plainText(nil)
+}
+
+// Comments by key are written after their values
+func (config *RawScheduleConfig) plainText(comments map[string]string) string {
+	var builder strings.Builder
+	builder.WriteString("[" + section + "]\n")
+	current := ""
+	for _, option := range config.Options() {
+		key := option.Key
+		subsection := ""
+		if k := strings.LastIndex(key, "."); k != -1 {
+			subsection, key = key[:k], key[k+1:]
+		}
+		if subsection != current {
+			builder.WriteString("[" + section + " " + strconv.Quote(subsection) + "]\n")
+			current = subsection
+		}
+		builder.WriteString("\t" + key + " = " + quote(option.Value))
+		if comment := comments[option.Key]; comment != "" {
+			builder.WriteString(" # " + comment)
+		}
+		builder.WriteString("\n")
+	}
+	return builder.String()
+}
+
+// Values with comment characters or quotes are quoted like git config does
+func quote(value string) string {
+	if !strings.ContainsAny(value, `;#"\`) {
+		return value
+	}
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `"`, `\"`)
+	return `"` + value + `"`
+}
+
+// DecentSections returns the [decent] sections of a git config file as they
+// are written, comments included
+func DecentSections(file string) string {
+	sections, _, _ := splitDecentSections(file)
+	return sections
+}
+
+// ReplaceDecentSections replaces the [decent] sections of a git config file
+// with the given ones, they are written where the first one was or at the end
+func ReplaceDecentSections(file string, sections string) string {
+	_, before, after := splitDecentSections(file)
+	if sections != "" && !strings.HasSuffix(sections, "\n") {
+		sections += "\n"
+	}
+	if before != "" && !strings.HasSuffix(before, "\n") {
+		before += "\n"
+	}
+	return before + sections + after
+}
+
+// The [decent] sections, the lines before the first one and the lines after
+// it that belong to other sections
+func splitDecentSections(file string) (string, string, string) {
+	var sections, before, after strings.Builder
+	inDecent := false
+	found := false
+	for _, line := range strings.SplitAfter(file, "\n") {
+		if name, isHeader := sectionName(line); isHeader {
+			inDecent = name == section
+			found = found || inDecent
+		}
+		switch {
+		case inDecent:
+			sections.WriteString(line)
+		case found:
+			after.WriteString(line)
+		default:
+			before.WriteString(line)
+		}
+	}
+	return sections.String(), before.String(), after.String()
+}
+
+// [decent], [decent "rule"] or the deprecated [decent.rule]
+func sectionName(line string) (string, bool) {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, "[") {
+		return "", false
+	}
+	end := strings.Index(line, "]")
+	if end == -1 {
+		return "", false
+	}
+	name := line[1:end]
+	if k := strings.IndexAny(name, ` ".`); k != -1 {
+		name = name[:k]
+	}
+	return strings.ToLower(strings.TrimSpace(name)), true
+}
@@ -0,0 +1,153 @@
+/* SPDX-License-Identifier: MIT */
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlainTextRoundTrip(t *testing.T) {
+	raw := RawScheduleConfig{
+		Days: map[time.Weekday]string{
+			time.Monday:   "09:00/13:00, 14:00/17:00",
+			time.Saturday: "10:00/12:00",
+			time.Sunday:   "22:00/02:00",
+		},
+		Timezone: "Europe/Berlin",
+		Off:      "2026-12-24..2026-12-26",
+		Override: "2026-11-02 10:00/12:00",
+		Holidays: "DE-BE",
+		Block:    "2026-11-03 13:00/15:00",
+		Rules: map[string]string{
+			"nineEighty": "FREQ=WEEKLY;INTERVAL=2;BYDAY=FR;DTSTART=20260109 off",
+			"standup":    "FREQ=WEEKLY;BYDAY=MO block 09:00/09:30",
+		},
+	}
+
+	text := raw.PlainText()
+	assert.Equal(t, `[decent]
+	Monday = 09:00/13:00, 14:00/17:00
+	Saturday = 10:00/12:00
+	Sunday = 22:00/02:00
+	Timezone = Europe/Berlin
+	Off = 2026-12-24..2026-12-26
+	Override = 2026-11-02 10:00/12:00
+	Holidays = DE-BE
+	Block = 2026-11-03 13:00/15:00
+[decent "rule"]
+	nineEighty = "FREQ=WEEKLY;INTERVAL=2;BYDAY=FR;DTSTART=20260109 off"
+	standup = "FREQ=WEEKLY;BYDAY=MO block 09:00/09:30"
+`, text)
+
+	parsed, err := NewScheduleFromPlainText(strings.NewReader(text))
+	require.NoError(t, err)
+	//Keys are read lowercased like git config does
+	assert.Equal(t, raw.Rules["nineEighty"], parsed.Rules["nineeighty"])
+	parsed.Rules = raw.Rules
+	assert.Equal(t, raw, *parsed)
+}
+
+func TestScheduleRaw(t *testing.T) {
+	raw := RawScheduleConfig{
+		Days: map[time.Weekday]string{
+			time.Monday:   "9-13, 14-17",
+			time.Saturday: "10am-12pm",
+		},
+		Timezone: "America/New_York",
+		Off:      "2026-12-24..2026-12-26, 2027-01-01",
+		Override: "2026-11-02 10-12",
+		Holidays: "us",
+		Block:    "2026-11-03 13:00/15:00",
+		Rules:    map[string]string{"firstmonday": "FREQ=MONTHLY;BYDAY=1MO 13:00/17:00"},
+	}
+	s, err := NewScheduleFromRaw(&raw)
+	require.NoError(t, err)
+
+	assert.Equal(t, RawScheduleConfig{
+		Days: map[time.Weekday]string{
+			time.Monday:   "09:00/13:00, 14:00/17:00",
+			time.Saturday: "10:00/12:00",
+		},
+		Timezone: "America/New_York",
+		Off:      "2026-12-24..2026-12-26, 2027-01-01",
+		Override: "2026-11-02 10:00/12:00",
+		Holidays: "US",
+		Block:    "2026-11-03 13:00/15:00",
+		Rules:    map[string]string{"firstmonday": "FREQ=MONTHLY;BYDAY=1MO 13:00/17:00"},
+	}, s.Raw())
+
+	//The marshalled configuration builds the same schedule
+	marshalled := s.Raw()
+	again, err := NewScheduleFromRaw(&marshalled)
+	require.NoError(t, err)
+	assert.Equal(t, s.Days, again.Days)
+	assert.Equal(t, s.Off, again.Off)
+	assert.Equal(t, s.Overrides, again.Overrides)
+	assert.Equal(t, s.Blocks, again.Blocks)
+}
+
+func TestScheduleRawWithIcsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "calendar.ics")
+	require.NoError(t, os.WriteFile(path, []byte(icsFixture), 0644))
+
+	raw := RawScheduleConfig{
+		Days:     map[time.Weekday]string{time.Monday: "10:00/18:00"},
+		Timezone: "Europe/Berlin",
+		Off:      "2027-01-01",
+		Block:    "2026-11-02 09:00/10:00",
+		IcsFile:  path,
+	}
+	s, err := NewScheduleFromRaw(&raw)
+	require.NoError(t, err)
+
+	//The calendar isn't copied, IcsFile brings it back
+	assert.Equal(t, RawScheduleConfig{
+		Days:     map[time.Weekday]string{time.Monday: "10:00/18:00"},
+		Timezone: "Europe/Berlin",
+		Off:      "2027-01-01",
+		Block:    "2026-11-02 09:00/10:00",
+		IcsFile:  path,
+	}, s.Raw())
+}
+
+func TestReplaceDecentSections(t *testing.T) {
+	file := `[core]
+	bare = false
+# Working hours
+[decent]
+	# Mornings only on Fridays
+	weekdays = 9-17
+	Fri = 9-13
+[remote "origin"]
+	url = git@example.com:repo.git
+[decent "rule"]
+	standup = "FREQ=WEEKLY;BYDAY=MO block 9-10"
+`
+
+	assert.Equal(t, `[decent]
+	# Mornings only on Fridays
+	weekdays = 9-17
+	Fri = 9-13
+[decent "rule"]
+	standup = "FREQ=WEEKLY;BYDAY=MO block 9-10"
+`, DecentSections(file))
+
+	assert.Equal(t, `[core]
+	bare = false
+# Working hours
+[decent]
+	weekend = 10-12
+[remote "origin"]
+	url = git@example.com:repo.git
+`, ReplaceDecentSections(file, "[decent]\n\tweekend = 10-12"))
+
+	assert.Equal(t, "[core]\n\tbare = false\n[decent]\n\tweekend = 10-12\n",
+		ReplaceDecentSections("[core]\n\tbare = false", "[decent]\n\tweekend = 10-12\n"))
+	assert.Empty(t, DecentSections("[core]\n\tbare = false\n"))
+}
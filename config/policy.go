@@ -0,0 +1,124 @@
+/* SPDX-License-Identifier: MIT */
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// PolicyFile is the team policy checked in the root of the repository, in
+// the git config format. Personal frames are narrowed to it.
+const PolicyFile = ".git-decent"
+
+// WeekFrames returns the decent time of a weekday without exceptions, the
+// overnight frames of the previous day included and narrowed to the policy
+func (s *Schedule) WeekFrames(day time.Weekday) TimeFrames {
+	spans := s.Days[day].Spans
+	if s.Policy != nil {
+		spans = intersectSpans(spans, s.Policy.Days[day].Spans)
+	}
+
+	frames := TimeFrames{}
+	for _, span := range spans {
+		frames = append(frames, span.frame())
+	}
+	return frames
+}
+
+// PolicyWidenings reports the personal frames, or parts of them, that the
+// team policy doesn't allow and therefore are not decent
+func (s *Schedule) PolicyWidenings() []error {
+	if s.Policy == nil {
+		return nil
+	}
+
+	errs := []error{}
+	for _, d := range weekdayOrder {
+		allowed := s.Policy.Days[d].Spans
+		for _, span := range s.Days[d].Spans {
+			for _, outside := range subtractSpans(DaySpans{span}, allowed) {
+				errs = append(errs, LintError{
+					Key: strings.ToLower(d.String()),
+					Msg: fmt.Sprintf("%s is outside the team policy, it isn't decent", outsideFrame(outside, allowed).Raw()),
+				})
+			}
+		}
+	}
+	return errs
+}
+
+// Frames include their end minute, the part of a frame outside another one
+// is written from or until the minute where the other one starts or ends
+func outsideFrame(outside DaySpan, allowed DaySpans) TimeFrame {
+	frame := outside.frame()
+	for _, span := range allowed {
+		if span.End == outside.Start {
+			frame.StartMinute--
+		}
+		if span.Start == outside.End {
+			frame.EndMinute++
+		}
+	}
+	return frame
+}
+
+// The frame covering the span, frames include their end minute
+func (d DaySpan) frame() TimeFrame {
+	return TimeFrame{StartMinute: d.Start / 60, EndMinute: d.End/60 - 1}
+}
+
+func intersectSpans(a DaySpans, b DaySpans) DaySpans {
+	spans := []DaySpan{}
+	for _, x := range a {
+		for _, y := range b {
+			if start, end := max(x.Start, y.Start), min(x.End, y.End); start < end {
+				spans = append(spans, DaySpan{Start: start, End: end})
+			}
+		}
+	}
+	return newDaySpans(spans)
+}
+
+func subtractSpans(a DaySpans, b DaySpans) DaySpans {
+	for _, y := range b {
+		remaining := []DaySpan{}
+		for _, x := range a {
+			if y.End <= x.Start || y.Start >= x.End {
+				remaining = append(remaining, x)
+				continue
+			}
+			if x.Start < y.Start {
+				remaining = append(remaining, DaySpan{Start: x.Start, End: y.Start})
+			}
+			if y.End < x.End {
+				remaining = append(remaining, DaySpan{Start: y.End, End: x.End})
+			}
+		}
+		a = remaining
+	}
+	return a
+}
+
+// Both lists are sorted and their intervals don't overlap
+func intersectIntervals(a []Interval, b []Interval, loc *time.Location) []Interval {
+	intervals := []Interval{}
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		start, end := a[i].Start, a[i].End
+		if b[j].Start.After(start) {
+			start = b[j].Start
+		}
+		if b[j].End.Before(end) {
+			end = b[j].End
+		}
+		if start.Before(end) {
+			intervals = append(intervals, Interval{Start: start.In(loc), End: end.In(loc)})
+		}
+		if a[i].End.Before(b[j].End) {
+			i++
+		} else {
+			j++
+		}
+	}
+	return intervals
+}
@@ -0,0 +1,59 @@
+/* SPDX-License-Identifier: MIT */
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicy(t *testing.T) {
+	s, err := NewScheduleFromMap(map[string]string{
+		"weekdays": "7-13, 14-19",
+		"saturday": "10-12",
+	})
+	require.NoError(t, err)
+	policy, err := NewScheduleFromMap(map[string]string{
+		"mon-fri": "8-18",
+		"off":     "2026-11-04",
+	})
+	require.NoError(t, err)
+	s.Policy = &policy
+
+	at := func(day int, hour int, min int) time.Time {
+		return time.Date(2026, time.November, day, hour, min, 0, 0, time.UTC)
+	}
+
+	//Personal frames are narrowed to the policy, they can't widen it
+	assert.False(t, s.Contains(at(2, 7, 30)))
+	assert.True(t, s.Contains(at(2, 8, 30)))
+	assert.False(t, s.Contains(at(2, 13, 30)))
+	assert.False(t, s.Contains(at(2, 18, 30)))
+	assert.False(t, s.Contains(at(7, 11, 0)))
+	assert.False(t, s.Contains(at(4, 10, 0)), "days off of the policy apply")
+
+	windows := s.Windows(at(2, 0, 0), at(5, 0, 0))
+	require.Len(t, windows, 4)
+	assertTime(t, at(2, 8, 0), windows[0].Start)
+	assertTime(t, at(2, 13, 1), windows[0].End)
+	assertTime(t, at(2, 14, 0), windows[1].Start)
+	assertTime(t, at(2, 18, 1), windows[1].End)
+	assertTime(t, at(3, 8, 0), windows[2].Start)
+	assertTime(t, at(3, 14, 0), windows[3].Start)
+
+	assertTime(t, at(2, 8, 0), s.Next(at(2, 7, 0)))
+	assertTime(t, at(5, 8, 0), s.Next(at(3, 19, 0)))
+	assertTime(t, at(9, 8, 0), s.Next(at(6, 18, 30)))
+	assertTime(t, at(6, 18, 0), s.Prev(at(7, 11, 0)))
+
+	assert.Equal(t, TimeFrames{{480, 780}, {840, 1080}}, s.WeekFrames(time.Monday))
+	assert.Empty(t, s.WeekFrames(time.Saturday))
+
+	assert.Equal(t, []error{
+		LintError{Key: "monday", Msg: "07:00/08:00 is outside the team policy, it isn't decent"},
+		LintError{Key: "monday", Msg: "18:00/19:00 is outside the team policy, it isn't decent"},
+	}, s.PolicyWidenings()[:2])
+	assert.Len(t, s.PolicyWidenings(), 11)
+}
@@ -0,0 +1,84 @@
+/* SPDX-License-Identifier: MIT */
+package config
+
+import (
+	"embed"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+//go:embed presets/*.ini
+var presetFiles embed.FS
+
+// DefaultPreset is the schedule offered when git decent is configured
+const DefaultPreset = "us-9to5"
+
+// A named schedule, days configured in git config win over the preset ones
+type Preset struct {
+	Name        string
+	Description string
+	// The preset as written in git config, with its description as a comment
+	Text   string
+	Config *RawScheduleConfig
+}
+
+// Presets returns the shipped presets sorted by name
+func Presets() []Preset {
+	entries, _ := presetFiles.ReadDir("presets")
+	presets := []Preset{}
+	for _, entry := range entries {
+		preset, err := loadPreset(strings.TrimSuffix(entry.Name(), ".ini"))
+		if err == nil {
+			presets = append(presets, *preset)
+		}
+	}
+	sort.Slice(presets, func(i, j int) bool {
+		return presets[i].Name < presets[j].Name
+	})
+	return presets
+}
+
+func PresetNames() []string {
+	names := []string{}
+	for _, preset := range Presets() {
+		names = append(names, preset.Name)
+	}
+	return names
+}
+
+// LookupPreset returns the preset with the given name, names are case insensitive
+func LookupPreset(name string) (*Preset, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	preset, err := loadPreset(name)
+	if err == nil {
+		return preset, nil
+	}
+
+	msg := fmt.Sprintf("unknown preset %s", name)
+	if suggestion := closest(name, PresetNames()); suggestion != "" {
+		msg += fmt.Sprintf(", did you mean %s?", suggestion)
+	}
+	return nil, fmt.Errorf("%s (available: %s)", msg, strings.Join(PresetNames(), ", "))
+}
+
+func loadPreset(name string) (*Preset, error) {
+	text, err := presetFiles.ReadFile(path.Join("presets", name+".ini"))
+	if err != nil {
+		return nil, err
+	}
+
+	rawC, err := NewScheduleFromPlainText(strings.NewReader(string(text)))
+	if err != nil {
+		return nil, fmt.Errorf("preset %s is broken: %w", name, err)
+	}
+
+	description, _, _ := strings.Cut(string(text), "\n")
+	return &Preset{
+		Name:        name,
+		Description: strings.TrimSpace(strings.TrimPrefix(description, "#")),
+		Text:        string(text),
+		Config:      rawC,
+	}, nil
+}
@@ -0,0 +1,52 @@
+/* SPDX-License-Identifier: MIT */
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPresets(t *testing.T) {
+	names := PresetNames()
+	assert.Contains(t, names, DefaultPreset)
+	assert.Contains(t, names, "eu-9to6")
+	assert.Contains(t, names, "four-day-week")
+
+	for _, preset := range Presets() {
+		assert.NotEmpty(t, preset.Description, preset.Name)
+		_, err := NewScheduleFromRaw(preset.Config)
+		assert.NoError(t, err, preset.Name)
+	}
+
+	_, err := LookupPreset("eu-9to5")
+	assert.ErrorContains(t, err, "unknown preset eu-9to5, did you mean eu-9to6?")
+}
+
+func TestScheduleWithPreset(t *testing.T) {
+	s, err := NewScheduleFromMap(map[string]string{
+		"preset": "EU-9to6",
+		"fri":    "9-13",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []TimeFrame{{540, 780}, {840, 1080}}, s.DecentTimeFrames(time.Monday))
+	assert.Equal(t, []TimeFrame{{540, 780}}, s.DecentTimeFrames(time.Friday))
+	assert.False(t, s.HasDecentTimeframe(time.Saturday))
+	assert.Equal(t, time.Monday, s.ClosestDecentDay(time.Saturday))
+
+	//The preset days aren't written, the preset brings them back
+	assert.Equal(t, RawScheduleConfig{
+		Preset: "EU-9to6",
+		Days:   map[time.Weekday]string{time.Friday: "09:00/13:00"},
+	}, s.Raw())
+
+	e := s.Export(map[string]string{"preset": "global", "fri": "local"})
+	assert.Equal(t, "preset", e.Days[0].Scope)
+	assert.Equal(t, "local", e.Days[4].Scope)
+
+	_, err = NewScheduleFromMap(map[string]string{"preset": "nine-to-five"})
+	assert.ErrorContains(t, err, "preset: unknown preset nine-to-five")
+}
@@ -0,0 +1,519 @@
+/* SPDX-License-Identifier: MIT */
+package config
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Frequency int
+
+const (
+	Daily Frequency = iota
+	Weekly
+	Monthly
+	Yearly
+)
+
+func (f Frequency) String() string {
+	return [...]string{"DAILY", "WEEKLY", "MONTHLY", "YEARLY"}[f]
+}
+
+// A weekday with an optional ordinal, 1MO is the first monday and -1FR the last friday
+type WeekdayNum struct {
+	N       int
+	Weekday time.Weekday
+}
+
+// Week parity, an extension to BYWEEKNO to express ISO odd or even weeks
+const (
+	oddWeeks  = -100
+	evenWeeks = -101
+)
+
+// Recurrence rule (RFC 5545) matched by calendar date, times are not
+// supported. Since it lives in a single line DTSTART is part of it.
+type RRule struct {
+	Freq       Frequency
+	Interval   int
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+	ByMonth    []time.Month
+	ByWeekNo   []int
+	DTStart    Date
+	Until      *Date
+	Count      int
+	// Start of the week for WEEKLY intervals
+	WeekStart time.Weekday
+	// Dates skipped, like EXDATE in iCalendar they don't change COUNT
+	ExDates []Date
+
+	hasDTStart bool
+}
+
+// Schedule exception that applies to every date matching the rule
+type Rule struct {
+	Name  string
+	RRule *RRule
+	// Days off when there are no frames
+	Off bool
+	// When Block is set frames are removed from the day instead of replacing it
+	Block  bool
+	Frames TimeFrames
+}
+
+// Rules that stop matching after too long are considered endless
+const maxRuleYears = 100
+
+// "FREQ=WEEKLY;INTERVAL=2;BYDAY=FR;DTSTART=20260109"
+func ParseRRule(rawRule string) (*RRule, error) {
+	r := &RRule{Interval: 1, WeekStart: time.Monday}
+	hasFreq := false
+	errs := []error{}
+
+	for _, part := range strings.Split(strings.TrimSpace(rawRule), ";") {
+		if part == "" {
+			continue
+		}
+		key, value, found := strings.Cut(part, "=")
+		if !found {
+			errs = append(errs, fmt.Errorf("rrule: %s should be KEY=VALUE", part))
+			continue
+		}
+
+		var err error
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			hasFreq = true
+			r.Freq, err = parseFrequency(value)
+		case "INTERVAL":
+			r.Interval, err = strconv.Atoi(value)
+			if err == nil && r.Interval < 1 {
+				err = fmt.Errorf("interval must be positive")
+			}
+		case "COUNT":
+			r.Count, err = strconv.Atoi(value)
+		case "UNTIL":
+			var until Date
+			until, err = parseRRuleDate(value)
+			r.Until = &until
+		case "DTSTART":
+			r.DTStart, err = parseRRuleDate(value)
+			r.hasDTStart = true
+		case "EXDATE":
+			for _, raw := range strings.Split(value, ",") {
+				var date Date
+				date, err = parseRRuleDate(strings.TrimSpace(raw))
+				if err != nil {
+					break
+				}
+				r.ExDates = append(r.ExDates, date)
+			}
+		case "WKST":
+			wd, exists := icsWeekdays[strings.ToUpper(value)]
+			if !exists {
+				err = fmt.Errorf("invalid weekday")
+			}
+			r.WeekStart = wd
+		case "BYDAY":
+			r.ByDay, err = parseByDay(value)
+		case "BYMONTHDAY":
+			r.ByMonthDay, err = parseIntList(value, -31, 31)
+		case "BYMONTH":
+			var months []int
+			months, err = parseIntList(value, 1, 12)
+			for _, m := range months {
+				r.ByMonth = append(r.ByMonth, time.Month(m))
+			}
+		case "BYWEEKNO":
+			switch strings.ToUpper(value) {
+			case "ODD":
+				r.ByWeekNo = []int{oddWeeks}
+			case "EVEN":
+				r.ByWeekNo = []int{evenWeeks}
+			default:
+				r.ByWeekNo, err = parseIntList(value, -53, 53)
+			}
+		default:
+			err = fmt.Errorf("not supported")
+		}
+
+		if err != nil {
+			errs = append(errs, fmt.Errorf("rrule: invalid %s: %w", part, err))
+		}
+	}
+
+	if !hasFreq {
+		errs = append(errs, fmt.Errorf("rrule: FREQ is required in %s", rawRule))
+	}
+	if r.Interval > 1 && !r.hasDTStart {
+		errs = append(errs, fmt.Errorf("rrule: INTERVAL needs DTSTART to know where to count from"))
+	}
+	if r.Count > 0 && !r.hasDTStart {
+		errs = append(errs, fmt.Errorf("rrule: COUNT needs DTSTART to know where to count from"))
+	}
+	if r.Freq != Daily && len(r.ByDay) == 0 && len(r.ByMonthDay) == 0 && !r.hasDTStart {
+		errs = append(errs, fmt.Errorf("rrule: BYDAY, BYMONTHDAY or DTSTART is needed to know which days repeat"))
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	//Counting is done once, from there on it is like an UNTIL
+	if r.Count > 0 {
+		count := r.Count
+		last := r.DTStart
+		for date := r.DTStart; count > 0 && date.Year-r.DTStart.Year < maxRuleYears; date = date.AddDays(1) {
+			if r.occurs(date) {
+				last = date
+				count--
+			}
+		}
+		if r.Until == nil || last.Before(*r.Until) {
+			r.Until = &last
+		}
+	}
+
+	return r, nil
+}
+
+func (r *RRule) Matches(date Date) bool {
+	for _, exDate := range r.ExDates {
+		if exDate == date {
+			return false
+		}
+	}
+	return r.occurs(date)
+}
+
+// The recurrence without the skipped dates
+func (r *RRule) occurs(date Date) bool {
+	if r.hasDTStart && date.Before(r.DTStart) {
+		return false
+	}
+	if r.Until != nil && r.Until.Before(date) {
+		return false
+	}
+	if !r.matchesInterval(date) {
+		return false
+	}
+
+	if len(r.ByMonth) > 0 && !containsMonth(r.ByMonth, date.Month) {
+		return false
+	}
+	if len(r.ByWeekNo) > 0 && !r.matchesWeekNo(date) {
+		return false
+	}
+	if len(r.ByMonthDay) > 0 && !matchesMonthDay(r.ByMonthDay, date) {
+		return false
+	}
+	if len(r.ByDay) > 0 {
+		return r.matchesByDay(date)
+	}
+
+	//Without BYDAY nor BYMONTHDAY the start date tells when it repeats
+	if len(r.ByMonthDay) > 0 || r.Freq == Daily {
+		return true
+	}
+	start := r.DTStart
+	switch {
+	case r.Freq == Weekly || len(r.ByWeekNo) > 0:
+		return date.Weekday() == start.Weekday()
+	case r.Freq == Monthly || len(r.ByMonth) > 0:
+		return date.Day == start.Day
+	}
+	return date.Day == start.Day && date.Month == start.Month
+}
+
+func (r *RRule) matchesInterval(date Date) bool {
+	if r.Interval == 1 {
+		return true
+	}
+
+	start := r.DTStart
+	var n int
+	switch r.Freq {
+	case Daily:
+		n = daysBetween(start, date)
+	case Weekly:
+		n = daysBetween(r.weekStartOf(start), r.weekStartOf(date)) / 7
+	case Monthly:
+		n = (date.Year-start.Year)*12 + int(date.Month-start.Month)
+	case Yearly:
+		n = date.Year - start.Year
+	}
+	return n%r.Interval == 0
+}
+
+func (r *RRule) weekStartOf(date Date) Date {
+	offset := (int(date.Weekday()) - int(r.WeekStart) + 7) % 7
+	return date.AddDays(-offset)
+}
+
+func (r *RRule) matchesWeekNo(date Date) bool {
+	year, week := date.Time(time.UTC).ISOWeek()
+	_, weeksInYear := Date{Year: year, Month: time.December, Day: 28}.Time(time.UTC).ISOWeek()
+	for _, w := range r.ByWeekNo {
+		switch {
+		case w == oddWeeks:
+			return week%2 == 1
+		case w == evenWeeks:
+			return week%2 == 0
+		case w < 0 && weeksInYear+w+1 == week:
+			return true
+		case w == week:
+			return true
+		}
+	}
+	return false
+}
+
+func (r *RRule) matchesByDay(date Date) bool {
+	for _, wd := range r.ByDay {
+		if wd.Weekday != date.Weekday() {
+			continue
+		}
+		if wd.N == 0 {
+			return true
+		}
+
+		//Ordinals count within the month, or the year for YEARLY without BYMONTH
+		var first, last Date
+		if r.Freq == Yearly && len(r.ByMonth) == 0 {
+			first = Date{Year: date.Year, Month: time.January, Day: 1}
+			last = Date{Year: date.Year, Month: time.December, Day: 31}
+		} else {
+			first = Date{Year: date.Year, Month: date.Month, Day: 1}
+			last = first.AddDays(32)
+			last = Date{Year: last.Year, Month: last.Month, Day: 1}.AddDays(-1)
+		}
+
+		if wd.N > 0 && daysBetween(first, date)/7+1 == wd.N {
+			return true
+		}
+		if wd.N < 0 && daysBetween(date, last)/7+1 == -wd.N {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesMonthDay(days []int, date Date) bool {
+	next := Date{Year: date.Year, Month: date.Month, Day: 1}.AddDays(32)
+	last := Date{Year: next.Year, Month: next.Month, Day: 1}.AddDays(-1)
+	for _, d := range days {
+		if d == date.Day || (d < 0 && last.Day+d+1 == date.Day) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsMonth(months []time.Month, month time.Month) bool {
+	for _, m := range months {
+		if m == month {
+			return true
+		}
+	}
+	return false
+}
+
+func daysBetween(from Date, to Date) int {
+	return int(to.Time(time.UTC).Sub(from.Time(time.UTC)).Hours() / 24)
+}
+
+func parseFrequency(value string) (Frequency, error) {
+	for f := Daily; f <= Yearly; f++ {
+		if strings.ToUpper(value) == f.String() {
+			return f, nil
+		}
+	}
+	return Daily, fmt.Errorf("supported frequencies are DAILY, WEEKLY, MONTHLY and YEARLY")
+}
+
+// 20260109 or 20260109T000000Z
+func parseRRuleDate(value string) (Date, error) {
+	if len(value) < len("20060102") {
+		return Date{}, fmt.Errorf("expected a date like 20060102")
+	}
+	t, err := time.Parse("20060102", value[:len("20060102")])
+	if err != nil {
+		return Date{}, fmt.Errorf("expected a date like 20060102")
+	}
+	return DateOf(t), nil
+}
+
+// MO,-1FR,2TU
+func parseByDay(value string) ([]WeekdayNum, error) {
+	days := []WeekdayNum{}
+	for _, raw := range strings.Split(value, ",") {
+		raw = strings.ToUpper(strings.TrimSpace(raw))
+		if len(raw) < 2 {
+			return nil, fmt.Errorf("invalid weekday %s", raw)
+		}
+		wd, exists := icsWeekdays[raw[len(raw)-2:]]
+		if !exists {
+			return nil, fmt.Errorf("invalid weekday %s", raw)
+		}
+		n := 0
+		if len(raw) > 2 {
+			var err error
+			n, err = strconv.Atoi(raw[:len(raw)-2])
+			if err != nil || n == 0 || n < -53 || n > 53 {
+				return nil, fmt.Errorf("invalid ordinal %s", raw)
+			}
+		}
+		days = append(days, WeekdayNum{N: n, Weekday: wd})
+	}
+	return days, nil
+}
+
+func parseIntList(value string, min int, max int) ([]int, error) {
+	list := []int{}
+	for _, raw := range strings.Split(value, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(raw))
+		if err != nil || n < min || n > max || n == 0 {
+			return nil, fmt.Errorf("%s out of range %d..%d", raw, min, max)
+		}
+		list = append(list, n)
+	}
+	return list, nil
+}
+
+func (r *RRule) String() string {
+	parts := []string{"FREQ=" + r.Freq.String()}
+	if r.Interval > 1 {
+		parts = append(parts, fmt.Sprintf("INTERVAL=%d", r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for k, wd := range r.ByDay {
+			days[k] = strings.ToUpper(wd.Weekday.String()[:2])
+			if wd.N != 0 {
+				days[k] = strconv.Itoa(wd.N) + days[k]
+			}
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		parts = append(parts, "BYMONTHDAY="+joinInts(r.ByMonthDay))
+	}
+	if len(r.ByMonth) > 0 {
+		months := make([]int, len(r.ByMonth))
+		for k, m := range r.ByMonth {
+			months[k] = int(m)
+		}
+		parts = append(parts, "BYMONTH="+joinInts(months))
+	}
+	if len(r.ByWeekNo) > 0 {
+		switch r.ByWeekNo[0] {
+		case oddWeeks:
+			parts = append(parts, "BYWEEKNO=ODD")
+		case evenWeeks:
+			parts = append(parts, "BYWEEKNO=EVEN")
+		default:
+			parts = append(parts, "BYWEEKNO="+joinInts(r.ByWeekNo))
+		}
+	}
+	if r.WeekStart != time.Monday {
+		parts = append(parts, "WKST="+strings.ToUpper(r.WeekStart.String()[:2]))
+	}
+	if r.hasDTStart {
+		parts = append(parts, "DTSTART="+r.DTStart.Time(time.UTC).Format("20060102"))
+	}
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.Time(time.UTC).Format("20060102"))
+	}
+	if len(r.ExDates) > 0 {
+		dates := make([]string, len(r.ExDates))
+		for k, date := range r.ExDates {
+			dates[k] = date.Time(time.UTC).Format("20060102")
+		}
+		parts = append(parts, "EXDATE="+strings.Join(dates, ","))
+	}
+	return strings.Join(parts, ";")
+}
+
+func joinInts(list []int) string {
+	str := make([]string, len(list))
+	for k, n := range list {
+		str[k] = strconv.Itoa(n)
+	}
+	return strings.Join(str, ",")
+}
+
+// "FREQ=WEEKLY;BYDAY=FR off", "FREQ=MONTHLY;BYDAY=1MO 13:00/17:00"
+// or "FREQ=WEEKLY;BYDAY=TU block 13:00/14:00"
+func parseRule(name string, rawRule string) (Rule, error) {
+	rule := Rule{Name: name}
+	fields := strings.Fields(rawRule)
+	if len(fields) < 2 {
+		return rule, fmt.Errorf("rule %s: expected a recurrence followed by off or frames", name)
+	}
+
+	rrule, err := ParseRRule(fields[0])
+	if err != nil {
+		return rule, fmt.Errorf("rule %s: %w", name, err)
+	}
+	rule.RRule = rrule
+
+	action := strings.Join(fields[1:], " ")
+	if strings.ToLower(action) == "off" {
+		rule.Off = true
+		return rule, nil
+	}
+
+	if rest, found := strings.CutPrefix(strings.ToLower(action), "block "); found {
+		rule.Block = true
+		action = rest
+	}
+
+	errs := []error{}
+	for _, raw := range strings.Split(action, ",") {
+		frame, err := parseFrame(strings.TrimSpace(raw))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("rule %s: %w", name, err))
+			continue
+		}
+		rule.Frames = append(rule.Frames, frame)
+	}
+	sortFrames(rule.Frames)
+
+	return rule, errors.Join(errs...)
+}
+
+func parseRules(rawRules map[string]string) ([]Rule, error) {
+	names := make([]string, 0, len(rawRules))
+	for name := range rawRules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	errs := []error{}
+	rules := []Rule{}
+	for _, name := range names {
+		rule, err := parseRule(name, rawRules[name])
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	return rules, errors.Join(errs...)
+}
+
+// Raw returns the rule in the git config format
+func (r Rule) Raw() string {
+	switch {
+	case r.Off:
+		return r.RRule.String() + " off"
+	case r.Block:
+		return r.RRule.String() + " block " + r.Frames.Raw()
+	}
+	return r.RRule.String() + " " + r.Frames.Raw()
+}
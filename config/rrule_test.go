@@ -0,0 +1,136 @@
+/* SPDX-License-Identifier: MIT */
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRRuleMatches(t *testing.T) {
+	everyOtherFriday, err := ParseRRule("FREQ=WEEKLY;INTERVAL=2;BYDAY=FR;DTSTART=20260109")
+	require.NoError(t, err)
+	assert.True(t, everyOtherFriday.Matches(Date{2026, time.January, 9}))
+	assert.False(t, everyOtherFriday.Matches(Date{2026, time.January, 16}))
+	assert.True(t, everyOtherFriday.Matches(Date{2026, time.January, 23}))
+	assert.False(t, everyOtherFriday.Matches(Date{2026, time.November, 6}))
+	assert.True(t, everyOtherFriday.Matches(Date{2026, time.November, 13}))
+	assert.False(t, everyOtherFriday.Matches(Date{2026, time.January, 2}), "before DTSTART")
+
+	firstMonday, err := ParseRRule("FREQ=MONTHLY;BYDAY=1MO")
+	require.NoError(t, err)
+	assert.True(t, firstMonday.Matches(Date{2026, time.November, 2}))
+	assert.False(t, firstMonday.Matches(Date{2026, time.November, 9}))
+	assert.True(t, firstMonday.Matches(Date{2026, time.December, 7}))
+
+	lastFriday, err := ParseRRule("FREQ=MONTHLY;BYDAY=-1FR")
+	require.NoError(t, err)
+	assert.True(t, lastFriday.Matches(Date{2026, time.November, 27}))
+	assert.False(t, lastFriday.Matches(Date{2026, time.November, 20}))
+
+	oddSaturdays, err := ParseRRule("FREQ=WEEKLY;BYDAY=SA;BYWEEKNO=ODD")
+	require.NoError(t, err)
+	assert.True(t, oddSaturdays.Matches(Date{2026, time.November, 7}))
+	assert.False(t, oddSaturdays.Matches(Date{2026, time.November, 14}))
+	assert.False(t, oddSaturdays.Matches(Date{2026, time.November, 6}))
+
+	lastDay, err := ParseRRule("FREQ=MONTHLY;BYMONTHDAY=-1")
+	require.NoError(t, err)
+	assert.True(t, lastDay.Matches(Date{2026, time.February, 28}))
+	assert.True(t, lastDay.Matches(Date{2026, time.November, 30}))
+	assert.False(t, lastDay.Matches(Date{2026, time.November, 29}))
+
+	count, err := ParseRRule("FREQ=DAILY;COUNT=3;DTSTART=20261102")
+	require.NoError(t, err)
+	assert.True(t, count.Matches(Date{2026, time.November, 4}))
+	assert.False(t, count.Matches(Date{2026, time.November, 5}))
+
+	until, err := ParseRRule("FREQ=WEEKLY;BYDAY=MO;UNTIL=20261109T235959Z")
+	require.NoError(t, err)
+	assert.True(t, until.Matches(Date{2026, time.November, 9}))
+	assert.False(t, until.Matches(Date{2026, time.November, 16}))
+
+	//Skipped dates still count
+	skipped, err := ParseRRule("FREQ=DAILY;COUNT=3;DTSTART=20261102;EXDATE=20261103")
+	require.NoError(t, err)
+	assert.False(t, skipped.Matches(Date{2026, time.November, 3}))
+	assert.True(t, skipped.Matches(Date{2026, time.November, 4}))
+	assert.False(t, skipped.Matches(Date{2026, time.November, 5}))
+
+	parsed, err := ParseRRule(everyOtherFriday.String())
+	require.NoError(t, err)
+	assert.Equal(t, everyOtherFriday, parsed)
+	assert.Equal(t, "FREQ=DAILY;DTSTART=20261102;UNTIL=20261104;EXDATE=20261103", skipped.String())
+}
+
+func TestRRuleError(t *testing.T) {
+	_, err := ParseRRule("BYDAY=FR")
+	assert.ErrorContains(t, err, "FREQ is required")
+
+	_, err = ParseRRule("FREQ=WEEKLY;INTERVAL=2;BYDAY=FR")
+	assert.ErrorContains(t, err, "INTERVAL needs DTSTART")
+
+	_, err = ParseRRule("FREQ=MONTHLY;BYSETPOS=1")
+	assert.ErrorContains(t, err, "BYSETPOS=1")
+
+	_, err = ParseRRule("FREQ=WEEKLY;BYDAY=XX")
+	assert.ErrorContains(t, err, "BYDAY=XX")
+
+	_, err = parseRule("broken", "FREQ=WEEKLY;BYDAY=FR")
+	assert.ErrorContains(t, err, "rule broken")
+}
+
+func TestScheduleRules(t *testing.T) {
+	raw := RawScheduleConfig{
+		Days: map[time.Weekday]string{
+			time.Monday: "09:00/17:00",
+			time.Friday: "09:00/17:00",
+		},
+		Rules: map[string]string{
+			"nineeighty":   "FREQ=WEEKLY;INTERVAL=2;BYDAY=FR;DTSTART=20260109 off",
+			"firstmonday":  "FREQ=MONTHLY;BYDAY=1MO 13:00/17:00",
+			"oddsaturdays": "FREQ=WEEKLY;BYDAY=SA;BYWEEKNO=ODD 10:00/12:00",
+			"standup":      "FREQ=WEEKLY;BYDAY=MO block 09:00/09:30",
+		},
+	}
+	s, err := NewScheduleFromRaw(&raw)
+	require.NoError(t, err)
+
+	assert.Empty(t, s.FramesOn(Date{2026, time.November, 13}))
+	assert.Equal(t, TimeFrames{{540, 1020}}, s.FramesOn(Date{2026, time.November, 6}))
+	assert.Equal(t, TimeFrames{{780, 1020}}, s.FramesOn(Date{2026, time.November, 2}))
+	assert.Equal(t, TimeFrames{{570, 1020}}, s.FramesOn(Date{2026, time.November, 9}))
+	assert.Equal(t, TimeFrames{{600, 720}}, s.FramesOn(Date{2026, time.November, 7}))
+	assert.Empty(t, s.FramesOn(Date{2026, time.November, 14}))
+
+	//Friday the 13th is off, next decent minute is Monday the 16th after the standup
+	layout := "2006-01-02 15:04"
+	pTime, err := time.Parse(layout, "2026-11-13 10:00")
+	require.NoError(t, err)
+	assertTime(t, pTime.Add(3*24*time.Hour-30*time.Minute), s.Next(pTime))
+
+	raw.Rules["broken"] = "FREQ=WEEKLY;BYDAY=FR maybe"
+	_, err = NewScheduleFromRaw(&raw)
+	assert.ErrorContains(t, err, "rule broken")
+}
+
+func TestScheduleRulesFromPlainText(t *testing.T) {
+	rawC, err := NewScheduleFromPlainText(strings.NewReader(`[decent]
+	Friday = 09:00/17:00 ; comment
+[decent "rule"]
+	nineEighty = "FREQ=WEEKLY;INTERVAL=2;BYDAY=FR;DTSTART=20260109 off" # comment
+`))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"nineeighty": "FREQ=WEEKLY;INTERVAL=2;BYDAY=FR;DTSTART=20260109 off",
+	}, rawC.Rules)
+
+	s, err := NewScheduleFromRaw(rawC)
+	require.NoError(t, err)
+	require.Len(t, s.Rules, 1)
+	assert.Equal(t, "nineeighty", s.Rules[0].Name)
+	assert.Empty(t, s.FramesOn(Date{2026, time.November, 13}))
+}
@@ -6,6 +6,8 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -36,34 +38,168 @@ func (t TimeFrames) String() string {
 }
 
 func (t TimeFrame) String() string {
-	return fmt.Sprintf("%02d:%02d - %02d:%02d", t.StartMinute/60, t.StartMinute%60, t.EndMinute/60, t.EndMinute%60)
+	end := t.EndMinute % dayMinutes
+	str := fmt.Sprintf("%02d:%02d - %02d:%02d", t.StartMinute/60, t.StartMinute%60, end/60, end%60)
+	if t.Overnight() {
+		str += " (+1)"
+	}
+	return str
+}
+
+// Raw returns the frame in the git config format, 09:00/17:00
+func (t TimeFrame) Raw() string {
+	end := t.EndMinute % dayMinutes
+	return fmt.Sprintf("%02d:%02d/%02d:%02d", t.StartMinute/60, t.StartMinute%60, end/60, end%60)
+}
+
+func (t TimeFrames) Raw() string {
+	raw := make([]string, len(t))
+	for k, frame := range t {
+		raw[k] = frame.Raw()
+	}
+	return strings.Join(raw, ", ")
+}
+
+func sortFrames(frames TimeFrames) {
+	sort.Slice(frames, func(i, j int) bool {
+		return frames[i].StartMinute < frames[j].StartMinute
+	})
+}
+
+// Overnight frames end after midnight, in the following day
+func (t TimeFrame) Overnight() bool {
+	return t.EndMinute >= dayMinutes
+}
+
+const dayMinutes = 24 * 60
+const daySeconds = dayMinutes * 60
+
+// Decent seconds of a day, from Start up to End (not included)
+type DaySpan struct {
+	Start int
+	End   int
+}
+
+// Sorted and merged spans within a single day
+type DaySpans []DaySpan
+
+func (d DaySpan) String() string {
+	return fmt.Sprintf("%s - %s", clock(d.Start), clock(d.End))
+}
+
+func clock(second int) string {
+	return fmt.Sprintf("%02d:%02d:%02d", second/3600, second/60%60, second%60)
+}
+
+// The seconds covered by a frame, its end minute included
+func (t TimeFrame) span() DaySpan {
+	return DaySpan{Start: t.StartMinute * 60, End: (t.EndMinute + 1) * 60}
+}
+
+// newDaySpans clips the spans to the day, sorts them and merges the ones
+// overlapping or touching
+func newDaySpans(spans []DaySpan) DaySpans {
+	sort.Slice(spans, func(i, j int) bool {
+		return spans[i].Start < spans[j].Start
+	})
+
+	merged := DaySpans{}
+	for _, span := range spans {
+		span.Start = max(span.Start, 0)
+		span.End = min(span.End, daySeconds)
+		if span.Start >= span.End {
+			continue
+		}
+
+		last := len(merged) - 1
+		if last >= 0 && span.Start <= merged[last].End {
+			merged[last].End = max(merged[last].End, span.End)
+			continue
+		}
+		merged = append(merged, span)
+	}
+	return merged
+}
+
+// Contains tells if the second of the day is decent, using a binary search
+func (d DaySpans) Contains(second int) bool {
+	k := sort.Search(len(d), func(i int) bool {
+		return d[i].End > second
+	})
+	return k < len(d) && d[k].Start <= second
 }
 
-// A list of day minutes that are work times
-type DayMinutes [1440]*TimeFrame
 type Day struct {
-	Minutes          DayMinutes
-	DecentFrames     TimeFrames
+	// Decent time of the day, overnight frames of the previous day included
+	Spans        DaySpans
+	DecentFrames TimeFrames
+	// Parts of the previous day overnight frames that continue into this day
+	SpilloverFrames  TimeFrames
 	ClosestDecentDay time.Weekday
+	// Where the frames come from when they are not configured, the
+	// preset or the calendar
+	origin string
+}
+
+func (d Day) Contains(second int) bool {
+	return d.Spans.Contains(second)
 }
 
 func (d Day) String() string {
-	str := "Minutes With frame:"
-	for k, v := range d.Minutes {
-		if v != nil {
-			str = fmt.Sprintf("%s %d", str, k)
-		}
+	str := "Spans:"
+	for _, v := range d.Spans {
+		str = fmt.Sprintf("%s %s,", str, v)
 	}
 	str = fmt.Sprintf("%s\nFrames", str)
 	for _, v := range d.DecentFrames {
 		str = fmt.Sprintf("%s\n\t%s", str, v)
 	}
+	str = fmt.Sprintf("%s\nSpillover", str)
+	for _, v := range d.SpilloverFrames {
+		str = fmt.Sprintf("%s\n\t%s", str, v)
+	}
 
 	return str
 }
 
 type Schedule struct {
 	Days [7]Day
+	// Location in which the frames are read, when nil the location of
+	// each date is used
+	Location *time.Location
+	// Dates without decent frames, holidays or time off
+	Off []DateRange
+	// Dates with their own frames instead of the weekday ones
+	Overrides map[Date]TimeFrames
+	// Public holidays without decent frames, nil when not configured
+	Holidays *HolidayCalendar
+	// Non decent time within a date, the end minute is not part of the block
+	Blocks map[Date]TimeFrames
+	// Recurring exceptions sorted by name
+	Rules []Rule
+	// Preset used for the days not configured
+	Preset string
+	// Team policy, only the time decent in both schedules is decent
+	Policy *Schedule
+	// When disabled any time the team policy allows is decent
+	Disabled bool
+	// How commits are amended, next-frame when empty
+	Strategy string
+	// Most commits amended into an hour or frame, nil when unlimited
+	Capacity *Capacity
+	// Least time between amended commits
+	MinGap time.Duration
+	// Real intervals between commits under it are kept
+	Threshold time.Duration
+	// Minutes added to moved commits, nil for a few from their own minute
+	Noise *Noise
+	// Most seconds set on moved commits, they keep theirs when 0
+	Jitter time.Duration
+	// Options of the remote and branch subsections, see Resolve
+	contexts map[string]map[string]string
+	// Calendar whose working hours, days off, blocks and rules are merged in
+	IcsFile  string
+	calendar *IcsCalendar
 }
 
 type ParseDayError struct {
@@ -72,12 +208,18 @@ type ParseDayError struct {
 }
 
 func NewScheduleFromMap(daysFrames map[string]string) (Schedule, error) {
-	rawC, err := GetGitRawConfig(&daysFrames)
+	return NewScheduleFor(daysFrames, Target{})
+}
+
+// NewScheduleFor builds the schedule of a remote and branch, see Resolve
+func NewScheduleFor(options map[string]string, target Target) (Schedule, error) {
+	rawC, err := GetGitRawConfig(&options)
 	if err != nil {
 		return Schedule{}, fmt.Errorf("couldn't get Raw config from map %w", err)
 	}
 
-	s, err := NewScheduleFromRaw(&rawC)
+	resolved := rawC.Resolve(target)
+	s, err := NewScheduleFromRaw(&resolved)
 	if err != nil {
 		return s, fmt.Errorf("couldn't get schedule from rawschedule %w", err)
 	}
@@ -89,10 +231,55 @@ func NewScheduleFromRaw(config *RawScheduleConfig) (Schedule, error) {
 	errs := []error{}
 
 	s := Schedule{}
+	if config.Timezone != "" {
+		loc, err := time.LoadLocation(config.Timezone)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid timezone %s: %w", config.Timezone, err))
+		}
+		s.Location = loc
+	}
+
+	origins := map[time.Weekday]string{}
+	days := map[time.Weekday]string{}
+	s.Preset = config.Preset
+	if config.Preset != "" {
+		preset, err := LookupPreset(config.Preset)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("preset: %w", err))
+		} else {
+			for d, v := range preset.Config.Days {
+				days[d] = v
+				origins[d] = presetOrigin
+			}
+		}
+	}
+
+	var ics *IcsCalendar
+	s.IcsFile = config.IcsFile
+	if config.IcsFile != "" {
+		var err error
+		ics, err = LoadIcsFile(config.IcsFile, s.Location)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("icsFile: %w", err))
+		}
+	}
+	//Working hours from the calendar fill the days not configured and win
+	//over the preset
+	if ics != nil {
+		for d, v := range ics.RawWorkingHours() {
+			days[d] = v
+			origins[d] = calendarOrigin
+		}
+	}
+	for d, v := range config.Days {
+		days[d] = v
+		delete(origins, d)
+	}
+
 	first := -1
 	daysWithout := 0
 	for d := time.Sunday; d <= time.Saturday; d++ {
-		v, exists := config.Days[d]
+		v, exists := days[d]
 		if !exists {
 			daysWithout++
 			continue
@@ -102,51 +289,121 @@ func NewScheduleFromRaw(config *RawScheduleConfig) (Schedule, error) {
 			first = int(d)
 		}
 		s.Days[d].ClosestDecentDay = d
+		s.Days[d].origin = origins[d]
 		tRange := parseFrames(v)
-		s.Days[d].DecentFrames = make([]TimeFrame, len(tRange))
-		for k, r := range tRange {
-			if len(r) != 11 {
-				err := fmt.Errorf("time range format should be 04:00/18:00 but instead %s given", r)
-				errs = append(errs, ParseDayError{Day: d, error: err})
-				continue
-			}
-
-			sMin := r[:5]
-			sTime, err := parseTime(sMin)
+		s.Days[d].DecentFrames = make([]TimeFrame, 0, len(tRange))
+		for _, r := range tRange {
+			timeFrame, err := parseFrame(r)
 			if err != nil {
-				errs = append(errs, err)
+				errs = append(errs, ParseDayError{Day: d, error: err})
 				continue
 			}
 
-			eMin := r[6:]
-			eTime, err := parseTime(eMin)
-			if err != nil {
-				errs = append(errs, err)
-				continue
+			s.Days[d].DecentFrames = append(s.Days[d].DecentFrames, timeFrame)
+			if timeFrame.Overnight() {
+				next := (d + 1) % 7
+				spill := TimeFrame{StartMinute: 0, EndMinute: timeFrame.EndMinute - dayMinutes}
+				s.Days[next].SpilloverFrames = append(s.Days[next].SpilloverFrames, spill)
 			}
+		}
+		for x := daysWithout; x > 0; x-- {
+			s.Days[int(d)-x].ClosestDecentDay = d
+		}
+		daysWithout = 0
+	}
+	//Days after the last configured one wrap around to the first
+	for x := daysWithout; x > 0 && first != -1; x-- {
+		s.Days[int(time.Saturday)+1-x].ClosestDecentDay = time.Weekday(first)
+	}
+	for d := range s.Days {
+		spans := []DaySpan{}
+		for _, frame := range s.Days[d].DecentFrames {
+			spans = append(spans, frame.span())
+		}
+		for _, frame := range s.Days[d].SpilloverFrames {
+			spans = append(spans, frame.span())
+		}
+		s.Days[d].Spans = newDaySpans(spans)
+	}
 
-			sMinute := sTime.hour*60 + sTime.minute
-			eMinute := eTime.hour*60 + eTime.minute
+	off, err := parseOff(config.Off)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	s.Off = off
 
-			if sMinute > eMinute {
-				errs = append(errs, fmt.Errorf("time range is inverse: %s is after %s", sMin, eMin))
-			}
+	overrides, err := parseDateFrames("override", config.Override)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	s.Overrides = overrides
 
-			timeFrame := TimeFrame{StartMinute: sMinute, EndMinute: eMinute}
-			s.Days[d].DecentFrames[k] = timeFrame
+	rules, err := parseRules(config.Rules)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	s.Rules = rules
 
-			for m := sMinute; m <= eMinute; m++ {
-				s.Days[d].Minutes[m] = &timeFrame
-			}
+	blocks, err := parseDateFrames("block", config.Block)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	s.Blocks = blocks
 
+	if ics != nil {
+		s.calendar = ics
+		s.Off = append(s.Off, ics.Off...)
+		if s.Blocks == nil {
+			s.Blocks = map[Date]TimeFrames{}
 		}
-		for x := daysWithout; x > 0; x-- {
-			s.Days[int(d)-x].ClosestDecentDay = d
+		for date, frames := range ics.Blocks {
+			s.Blocks[date] = append(s.Blocks[date], frames...)
+			sortFrames(s.Blocks[date])
 		}
-		daysWithout = 0
+		s.Rules = append(s.Rules, ics.Rules...)
+	}
+
+	if config.Enabled != "" {
+		enabled, err := parseBool(config.Enabled)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("enabled: %w", err))
+		}
+		s.Disabled = !enabled
+	}
+	s.Strategy = strings.ToLower(config.Strategy)
+	capacity, err := parseCapacity(config.Capacity)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	s.Capacity = capacity
+	gap, err := parseMinutes("mingap", config.MinGap)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	s.MinGap = gap
+	threshold, err := parseMinutes("threshold", config.Threshold)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	s.Threshold = threshold
+	noise, err := parseNoise(config.Noise)
+	if err != nil {
+		errs = append(errs, err)
 	}
-	if len(s.Days[time.Saturday].DecentFrames) == 0 {
-		s.Days[time.Saturday].ClosestDecentDay = time.Weekday(first)
+	s.Noise = noise
+	jitter, err := parseJitter(config.Jitter)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	s.Jitter = jitter
+	s.contexts = config.Contexts
+
+	if strings.TrimSpace(config.Holidays) != "" {
+		holidays, err := NewHolidayCalendar(config.Holidays)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		s.Holidays = holidays
 	}
 
 	if len(errs) > 0 {
@@ -155,49 +412,92 @@ func NewScheduleFromRaw(config *RawScheduleConfig) (Schedule, error) {
 	return s, nil
 }
 
+// 10:00/11:00, 9-17 or 9am-1pm, frames ending before they start cross
+// midnight into the next day
+func parseFrame(r string) (TimeFrame, error) {
+	rawStart, rawEnd, found := strings.Cut(r, "/")
+	if !found {
+		rawStart, rawEnd, found = strings.Cut(r, "-")
+	}
+	if !found {
+		return TimeFrame{}, fmt.Errorf("time range format should be 09:00/17:00, 9-17 or 9am-1pm but instead %q given", r)
+	}
+
+	sTime, err := parseTime(strings.TrimSpace(rawStart))
+	if err != nil {
+		return TimeFrame{}, fmt.Errorf("frame %q: %w", r, err)
+	}
+
+	eTime, err := parseTime(strings.TrimSpace(rawEnd))
+	if err != nil {
+		return TimeFrame{}, fmt.Errorf("frame %q: %w", r, err)
+	}
+
+	timeFrame := TimeFrame{
+		StartMinute: sTime.hour*60 + sTime.minute,
+		EndMinute:   eTime.hour*60 + eTime.minute,
+	}
+	if timeFrame.StartMinute > timeFrame.EndMinute {
+		timeFrame.EndMinute += dayMinutes
+	}
+
+	return timeFrame, nil
+}
+
 // "10:00/11:00, 13:00/14:00"
 func parseFrames(rawFrames string) []string {
-	var frames []string
-	s := 0
-	for c := 0; c < len(rawFrames); c++ {
-		if rawFrames[c] == ' ' {
-			s = c + 1
-			continue
-		}
-		if rawFrames[c] == ',' {
-			frames = append(frames, rawFrames[s:c])
-			s = c + 1
-		}
+	frames := strings.Split(rawFrames, ",")
+	for k := range frames {
+		frames[k] = strings.TrimSpace(frames[k])
 	}
-
-	frames = append(frames, rawFrames[s:])
 	return frames
 }
 
-// 15:04
+// validateFrames reports every frame that can't be parsed
+func validateFrames(rawFrames string) error {
+	errs := []error{}
+	for _, r := range parseFrames(rawFrames) {
+		if _, err := parseFrame(r); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+var timeFormat = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?\s*(am|pm)?$`)
+
+// 15:04, 9:30, 9, 9am or 1:30pm
 func parseTime(time string) (HourMin, error) {
 	hourMin := HourMin{}
 
-	if len(time) > 5 || time[2] != ':' {
-		return hourMin, fmt.Errorf("incorrect time format, expected 15:04 but given %s", time)
+	m := timeFormat.FindStringSubmatch(strings.ToLower(time))
+	if m == nil {
+		return hourMin, fmt.Errorf("incorrect time format, expected 15:04, 9 or 9am but given %q", time)
 	}
 
-	hour, err := strconv.Atoi(time[:2])
-	if err != nil {
-		return hourMin, fmt.Errorf("couldn't parse hour: %s error: %w", time[:2], err)
+	hour, _ := strconv.Atoi(m[1])
+	min := 0
+	if m[2] != "" {
+		min, _ = strconv.Atoi(m[2])
 	}
 
-	min, err := strconv.Atoi(time[3:])
-	if err != nil {
-		return hourMin, fmt.Errorf("couldn't parse minutes: %s error: %w", time[:3], err)
+	if m[3] != "" {
+		if hour < 1 || hour > 12 {
+			return hourMin, fmt.Errorf("hour out of range in %q, use 1 to 12 with am or pm", time)
+		}
+		//12am is midnight and 12pm noon
+		hour %= 12
+		if m[3] == "pm" {
+			hour += 12
+		}
 	}
 
-	if hour < 0 || hour > 23 {
-		return hourMin, fmt.Errorf("hour out of range, hour: %d", hour)
+	if hour > 23 {
+		return hourMin, fmt.Errorf("hour out of range in %q, hour: %d", time, hour)
 	}
 
-	if min < 0 || min > 59 {
-		return hourMin, fmt.Errorf("time out of range, hour: minute: %d", min)
+	if min > 59 {
+		return hourMin, fmt.Errorf("minute out of range in %q, minute: %d", time, min)
 	}
 
 	hourMin.hour = hour
@@ -212,95 +512,136 @@ func NewScheduleFromPlainText(plainText io.Reader) (*RawScheduleConfig, error) {
 	}
 	s := bufio.NewScanner(plainText)
 
+	type entry struct {
+		line  int
+		key   string
+		value string
+	}
+	entries := []entry{}
+
+	prefix := ""
+	inDecent := true
+	n := 0
 	for s.Scan() {
-		line := s.Text()
-		if i := strings.Index(line, "[decent]"); i != -1 {
+		n++
+		line := strings.TrimSpace(stripComment(s.Text()))
+		if len(line) == 0 {
 			continue
 		}
 
-		if i := strings.Index(line, "#"); i != -1 {
-			line = line[:i]
-		}
-
-		line = strings.TrimSpace(line)
-		if len(line) == 0 {
+		//[decent] or [decent "rule"]
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			name, sub, _ := strings.Cut(line[1:len(line)-1], " ")
+			inDecent = strings.ToLower(name) == section
+			prefix = ""
+			if sub = strings.Trim(strings.TrimSpace(sub), `"`); sub != "" {
+				prefix = sub + "."
+			}
 			continue
 		}
 
 		eq := strings.Index(line, "=")
-		if eq == -1 {
+		if eq == -1 || !inDecent {
 			continue
 		}
 
-		day := strings.TrimSpace(strings.ToLower(line[:eq]))
-		frames := strings.TrimSpace(line[eq+1:])
-
-		switch day {
-		case "monday":
-			rawC.Days[time.Monday] = frames
-		case "tuesday":
-			rawC.Days[time.Tuesday] = frames
-		case "wednesday":
-			rawC.Days[time.Wednesday] = frames
-		case "thursday":
-			rawC.Days[time.Thursday] = frames
-		case "friday":
-			rawC.Days[time.Friday] = frames
-		case "saturday":
-			rawC.Days[time.Saturday] = frames
-		case "sunday":
-			rawC.Days[time.Sunday] = frames
-		default:
-			return nil, fmt.Errorf("found a weekday that can't be handled %s", day)
+		entries = append(entries, entry{
+			line:  n,
+			key:   prefix + strings.TrimSpace(strings.ToLower(line[:eq])),
+			value: unquote(strings.TrimSpace(line[eq+1:])),
+		})
+	}
+
+	//Like in git config single days win over ranges
+	sort.SliceStable(entries, func(i, j int) bool {
+		return len(daysOf(entries[i].key)) > len(daysOf(entries[j].key))
+	})
+
+	errs := make([]error, n+1)
+	for _, e := range entries {
+		err := rawC.SetValue(e.key, e.value)
+		if err != nil {
+			errs[e.line] = fmt.Errorf("line %d: %w", e.line, err)
 		}
 	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
 
 	return &rawC, nil
 }
 
+// Like git config, # and ; start a comment unless they are quoted
+func stripComment(line string) string {
+	quoted := false
+	for k := 0; k < len(line); k++ {
+		switch line[k] {
+		case '\\':
+			k++
+		case '"':
+			quoted = !quoted
+		case '#', ';':
+			if !quoted {
+				return line[:k]
+			}
+		}
+	}
+	return line
+}
+
+func unquote(value string) string {
+	var builder strings.Builder
+	for k := 0; k < len(value); k++ {
+		switch {
+		case value[k] == '\\' && k+1 < len(value):
+			k++
+			builder.WriteByte(value[k])
+		case value[k] != '"':
+			builder.WriteByte(value[k])
+		}
+	}
+	return builder.String()
+}
+
 func (s *Schedule) HasDecentTimeframe(day time.Weekday) bool {
-	return false
+	return len(s.Days[day].DecentFrames) > 0
 }
 
 func (s *Schedule) DecentTimeFrames(day time.Weekday) []TimeFrame {
 	return s.Days[day].DecentFrames
 }
 
-func (s *Schedule) ClosestDecentDay(day time.Weekday) (time.Weekday, int) {
-	next := s.Days[day].ClosestDecentDay
-	return next, (int(next-day) + 7) % 7
+// DayOrigin returns where the frames of a day come from when they are not
+// configured, preset or calendar
+func (s *Schedule) DayOrigin(day time.Weekday) string {
+	return s.Days[day].origin
 }
 
-func (s *Schedule) ClosestDecentMinute(date time.Time) (int, int) {
-	wDay := date.Weekday()
-	dMin := DayMinute(date)
-	frameMin := s.Days[wDay].Minutes[dMin]
-	if frameMin != nil {
-		return dMin, 0
-	}
+// ClosestDecentDay returns the first weekday from day on with decent frames,
+// use Next to take exceptions into account
+func (s *Schedule) ClosestDecentDay(day time.Weekday) time.Weekday {
+	return s.Days[day].ClosestDecentDay
+}
 
-	for _, frame := range s.Days[wDay].DecentFrames {
-		if dMin <= frame.EndMinute {
-			return frame.StartMinute, frame.StartMinute - dMin
-		}
-	}
+// LeavesCommits tells if commits are left as they are, the team policy still
+// applies when only the personal schedule is disabled
+func (s *Schedule) LeavesCommits() bool {
+	return s.Disabled && (s.Policy == nil || s.Policy.LeavesCommits())
+}
 
-	nextDay := wDay + 1
-	if nextDay > 6 {
-		nextDay = 0
+// In returns the date in the schedule location
+func (s *Schedule) In(date time.Time) time.Time {
+	if s.Location == nil {
+		return date
 	}
-	day, nDay := s.ClosestDecentDay(nextDay)
-	nDay++
-	frame := &s.Days[day].DecentFrames[0]
-	hoursToaDd := 24 - date.Hour()
-
-	hoursToaDd += (nDay - 1) * 24
-	return frame.StartMinute, hoursToaDd*60 + frame.StartMinute - date.Minute()
-
+	return date.In(s.Location)
 }
 
 func (s Schedule) String() string {
 	ss := ""
+	if s.Location != nil {
+		ss = fmt.Sprintf("Timezone %s\n", s.Location)
+	}
 	for day, sch := range s.Days {
 		ss = fmt.Sprintf("%s%s has %d decent frames next %s\n\t", ss, time.Weekday(day), len(sch.DecentFrames), sch.ClosestDecentDay)
 		for _, timeFrame := range sch.DecentFrames {
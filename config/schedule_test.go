@@ -12,9 +12,8 @@ import (
 func TestScheduleFromRawError(t *testing.T) {
 	raw := RawScheduleConfig{
 		Days: map[time.Weekday]string{
-			time.Monday:    "09:00",
-			time.Tuesday:   "28:00/29:00",
-			time.Wednesday: "10:00/00:00",
+			time.Monday:  "09:00",
+			time.Tuesday: "28:00/29:00",
 		},
 	}
 
@@ -22,7 +21,58 @@ func TestScheduleFromRawError(t *testing.T) {
 	assert.Error(t, err, "Parsing should fail")
 	assert.ErrorContains(t, err, "time range format should be")
 	assert.ErrorContains(t, err, "hour out of range")
-	assert.ErrorContains(t, err, "time range is inverse")
+}
+
+func TestScheduleOvernight(t *testing.T) {
+	raw := RawScheduleConfig{
+		Days: map[time.Weekday]string{
+			time.Monday:   "09:00/17:00",
+			time.Friday:   "22:00/02:00",
+			time.Saturday: "23:00/01:00",
+		},
+	}
+	s, err := NewScheduleFromRaw(&raw)
+	require.NoError(t, err)
+
+	frames := s.DecentTimeFrames(time.Friday)
+	require.Len(t, frames, 1)
+	assert.True(t, frames[0].Overnight())
+	assert.Equal(t, "22:00 - 02:00 (+1)", frames[0].String())
+
+	assert.True(t, s.Days[time.Friday].Contains((23*60+59)*60))
+	assert.False(t, s.Days[time.Friday].Contains((21*60+59)*60))
+	assert.True(t, s.Days[time.Saturday].Contains(0*60))
+	assert.True(t, s.Days[time.Saturday].Contains((2*60)*60))
+	assert.False(t, s.Days[time.Saturday].Contains((2*60+1)*60))
+	assert.Equal(t, TimeFrames{{StartMinute: 0, EndMinute: 2 * 60}}, s.Days[time.Saturday].SpilloverFrames)
+
+	//Saturday frame wraps into Sunday
+	assert.True(t, s.Days[time.Sunday].Contains(60*60))
+	assert.False(t, s.Days[time.Sunday].Contains(61*60))
+
+	layout := "2006-01-02 15:04:05"
+	//Saturday 01:30 is still part of Friday frame
+	pTime, err := time.Parse(layout, "2024-02-03 01:30:00")
+	require.NoError(t, err)
+	assert.True(t, s.Contains(pTime))
+	assertTime(t, pTime, s.Next(pTime))
+
+	//Saturday 03:00 moves to the Saturday night frame
+	pTime, err = time.Parse(layout, "2024-02-03 03:00:00")
+	require.NoError(t, err)
+	assert.False(t, s.Contains(pTime))
+	assertTime(t, pTime.Add(20*time.Hour), s.Next(pTime))
+
+	//Sunday 02:00 moves to Monday
+	pTime, err = time.Parse(layout, "2024-02-04 02:00:00")
+	require.NoError(t, err)
+	assertTime(t, pTime.Add(31*time.Hour), s.Next(pTime))
+
+	//Tuesday moves to the Friday night frame
+	pTime, err = time.Parse(layout, "2024-01-30 10:00:00")
+	require.NoError(t, err)
+	assertTime(t, pTime.Add(3*24*time.Hour+12*time.Hour), s.Next(pTime))
+	assert.Equal(t, time.Friday, s.Days[time.Tuesday].ClosestDecentDay)
 }
 
 func TestScheduleFromRaw(t *testing.T) {
@@ -37,26 +87,24 @@ func TestScheduleFromRaw(t *testing.T) {
 	assert.Nil(t, err)
 	sminute := 9 * 60
 	eminute := 17 * 60
-	assert.NotNil(t, s.Days[time.Monday].Minutes[sminute])
-	assert.Nil(t, s.Days[time.Monday].Minutes[sminute-1])
-	assert.NotNil(t, s.Days[time.Monday].Minutes[eminute])
-	assert.Nil(t, s.Days[time.Monday].Minutes[eminute+1])
-	assert.Nil(t, s.Days[time.Monday].Minutes[18*60-1])
-	assert.Nil(t, s.Days[time.Monday].Minutes[18*60-1])
-	assert.Nil(t, s.Days[time.Monday].Minutes[20*60])
-	assert.NotNil(t, s.Days[time.Tuesday].Minutes[10*60])
-	assert.Nil(t, s.Days[time.Tuesday].Minutes[10*60-1])
-	assert.NotNil(t, s.Days[time.Tuesday].Minutes[10*60+1])
-	assert.NotNil(t, s.Days[time.Tuesday].Minutes[11*60])
+	assert.True(t, s.Days[time.Monday].Contains(sminute*60))
+	assert.False(t, s.Days[time.Monday].Contains((sminute-1)*60))
+	assert.True(t, s.Days[time.Monday].Contains(eminute*60))
+	assert.False(t, s.Days[time.Monday].Contains((eminute+1)*60))
+	assert.False(t, s.Days[time.Monday].Contains((18*60-1)*60))
+	assert.False(t, s.Days[time.Monday].Contains((18*60-1)*60))
+	assert.False(t, s.Days[time.Monday].Contains((20*60)*60))
+	assert.True(t, s.Days[time.Tuesday].Contains((10*60)*60))
+	assert.False(t, s.Days[time.Tuesday].Contains((10*60-1)*60))
+	assert.True(t, s.Days[time.Tuesday].Contains((10*60+1)*60))
+	assert.True(t, s.Days[time.Tuesday].Contains((11*60)*60))
 
 	t.Run("Test Frames", func(t *testing.T) {
 		assert.False(t, s.HasDecentTimeframe(time.Wednesday))
+		assert.True(t, s.HasDecentTimeframe(time.Monday))
 		assert.Len(t, s.DecentTimeFrames(time.Monday), 2)
 
-		frames := s.DecentTimeFrames(time.Monday)
-
-		assert.Equal(t, frames[0], *s.Days[time.Monday].Minutes[sminute])
-		assert.Equal(t, frames[1], *s.Days[time.Monday].Minutes[18*60])
+		assert.Equal(t, DaySpans{{9 * 3600, 17*3600 + 60}, {18 * 3600, 19*3600 + 60}}, s.Days[time.Monday].Spans)
 	})
 }
 
@@ -67,10 +115,12 @@ func TestScheduleFromPlainText(t *testing.T) {
 	     Wednesday= 09:00/13:00, 14:00/17:00
 	     Thursday =09:00/13:00, 14:00/17:00
 	     Friday = 09:00/13:00, 14:00/17:00
+	     Timezone = Europe/Berlin
 	`
 
 	rawC, err := NewScheduleFromPlainText(strings.NewReader(plainText))
 	assert.NoError(t, err)
+	assert.Equal(t, "Europe/Berlin", rawC.Timezone)
 	s, err := NewScheduleFromRaw(rawC)
 	assert.NoError(t, err)
 
@@ -80,12 +130,12 @@ func TestScheduleFromPlainText(t *testing.T) {
 	assert.Equal(t, time.Wednesday, s.Days[time.Wednesday].ClosestDecentDay)
 	assert.Equal(t, time.Thursday, s.Days[time.Thursday].ClosestDecentDay)
 	assert.Equal(t, time.Friday, s.Days[time.Friday].ClosestDecentDay)
-	assert.NotNil(t, s.Days[time.Monday].Minutes[60*9])
-	assert.NotNil(t, s.Days[time.Tuesday].Minutes[60*10])
-	assert.NotNil(t, s.Days[time.Wednesday].Minutes[60*13])
-	assert.NotNil(t, s.Days[time.Thursday].Minutes[60*14])
-	assert.NotNil(t, s.Days[time.Friday].Minutes[60*17])
-	assert.Nil(t, s.Days[time.Saturday].Minutes[60*9])
+	assert.True(t, s.Days[time.Monday].Contains((60*9)*60))
+	assert.True(t, s.Days[time.Tuesday].Contains((60*10)*60))
+	assert.True(t, s.Days[time.Wednesday].Contains((60*13)*60))
+	assert.True(t, s.Days[time.Thursday].Contains((60*14)*60))
+	assert.True(t, s.Days[time.Friday].Contains((60*17)*60))
+	assert.False(t, s.Days[time.Saturday].Contains((60*9)*60))
 }
 func TestClosestDecentDay(t *testing.T) {
 	raw := RawScheduleConfig{
@@ -100,28 +150,14 @@ func TestClosestDecentDay(t *testing.T) {
 	schedule, err := NewScheduleFromRaw(&raw)
 	require.NoError(t, err)
 
-	d, nDays := schedule.ClosestDecentDay(time.Monday)
-	assert.Equal(t, d, time.Monday)
-	assert.Equal(t, nDays, 0)
-
-	d, nDays = schedule.ClosestDecentDay(time.Tuesday)
-	assert.Equal(t, d, time.Wednesday)
-	assert.Equal(t, nDays, 1)
-
-	d, nDays = schedule.ClosestDecentDay(time.Wednesday)
-	assert.Equal(t, d, time.Wednesday)
-	assert.Equal(t, nDays, 0)
-
-	d, nDays = schedule.ClosestDecentDay(time.Thursday)
-	assert.Equal(t, d, time.Friday)
-	assert.Equal(t, nDays, 1)
-
-	d, nDays = schedule.ClosestDecentDay(time.Saturday)
-	assert.Equal(t, d, time.Monday)
-	assert.Equal(t, nDays, 2)
+	assert.Equal(t, time.Monday, schedule.ClosestDecentDay(time.Monday))
+	assert.Equal(t, time.Wednesday, schedule.ClosestDecentDay(time.Tuesday))
+	assert.Equal(t, time.Wednesday, schedule.ClosestDecentDay(time.Wednesday))
+	assert.Equal(t, time.Friday, schedule.ClosestDecentDay(time.Thursday))
+	assert.Equal(t, time.Monday, schedule.ClosestDecentDay(time.Saturday))
 }
 
-func TestClosestDecentMinute(t *testing.T) {
+func TestNext(t *testing.T) {
 	raw := RawScheduleConfig{
 		Days: map[time.Weekday]string{
 			time.Monday:    "10:00/11:00, 13:00/14:00",
@@ -137,37 +173,27 @@ func TestClosestDecentMinute(t *testing.T) {
 	pTime, err := time.Parse(layout, "2024-01-28 18:30:00")
 	assert.NoError(t, err)
 
-	minute, nMins := schedule.ClosestDecentMinute(pTime)
-	assert.Equal(t, 930, nMins)
-	assert.Equal(t, 10*60, minute)
+	assertTime(t, pTime.Add(930*time.Minute), schedule.Next(pTime))
 
 	pTime, err = time.Parse(layout, "2024-01-29 09:59:00")
 	assert.NoError(t, err)
 
-	minute, nMins = schedule.ClosestDecentMinute(pTime)
-	assert.Equal(t, 1, nMins)
-	assert.Equal(t, 10*60, minute)
+	assertTime(t, pTime.Add(time.Minute), schedule.Next(pTime))
 
 	pTime, err = time.Parse(layout, "2024-01-29 11:01:00")
 	assert.NoError(t, err)
 
-	minute, nMins = schedule.ClosestDecentMinute(pTime)
-	assert.Equal(t, 2*60-1, nMins)
-	assert.Equal(t, 13*60, minute)
+	assertTime(t, pTime.Add(2*time.Hour-time.Minute), schedule.Next(pTime))
 
 	pTime, err = time.Parse(layout, "2024-01-29 14:01:00")
 	assert.NoError(t, err)
 
-	minute, nMins = schedule.ClosestDecentMinute(pTime)
-	assert.Equal(t, 2639, nMins)
-	assert.Equal(t, 10*60, minute)
+	assertTime(t, pTime.Add(2639*time.Minute), schedule.Next(pTime))
 
 	pTime, err = time.Parse(layout, "2024-01-27 14:01:00")
 	assert.NoError(t, err)
 
-	minute, nMins = schedule.ClosestDecentMinute(pTime)
-	assert.Equal(t, 2639, nMins)
-	assert.Equal(t, 10*60, minute)
+	assertTime(t, pTime.Add(2639*time.Minute), schedule.Next(pTime))
 }
 
 func BenchmarkNewScheduleFromRaw(b *testing.B) {
@@ -183,3 +209,192 @@ func BenchmarkNewScheduleFromRaw(b *testing.B) {
 		NewScheduleFromRaw(&raw)
 	}
 }
+
+func TestScheduleTimezone(t *testing.T) {
+	raw := RawScheduleConfig{
+		Days: map[time.Weekday]string{
+			time.Monday: "09:00/17:00",
+		},
+		Timezone: "Europe/Berlin",
+	}
+
+	schedule, err := NewScheduleFromRaw(&raw)
+	require.NoError(t, err)
+	require.NotNil(t, schedule.Location)
+	assert.Equal(t, "Europe/Berlin", schedule.Location.String())
+
+	//09:30 in New York is 15:30 in Berlin, inside the frame
+	ny, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	pTime := time.Date(2024, 1, 29, 9, 30, 0, 0, ny)
+	assert.True(t, schedule.Contains(pTime))
+	next := schedule.Next(pTime)
+	assertTime(t, pTime, next)
+	assert.Equal(t, "15:30", next.Format("15:04"))
+
+	//06:00 UTC is 07:00 in Berlin, two hours before the frame
+	pTime = time.Date(2024, 1, 29, 6, 0, 0, 0, time.UTC)
+	assertTime(t, pTime.Add(2*time.Hour), schedule.Next(pTime))
+
+	raw.Timezone = "Europe/Nowhere"
+	_, err = NewScheduleFromRaw(&raw)
+	assert.ErrorContains(t, err, "invalid timezone")
+}
+
+func TestScheduleExceptions(t *testing.T) {
+	raw := RawScheduleConfig{
+		Days: map[time.Weekday]string{
+			time.Monday:    "09:00/17:00",
+			time.Tuesday:   "09:00/17:00",
+			time.Wednesday: "09:00/17:00",
+			time.Thursday:  "09:00/17:00",
+			time.Friday:    "09:00/17:00",
+		},
+		Off:      "2026-12-24..2026-12-26, 2027-01-01",
+		Override: "2026-11-02 10:00/12:00, 13:00/14:00, 2026-11-07 10:00/11:00",
+	}
+	s, err := NewScheduleFromRaw(&raw)
+	require.NoError(t, err)
+
+	assert.Len(t, s.Off, 2)
+	assert.True(t, s.IsOff(Date{2026, time.December, 25}))
+	assert.True(t, s.IsOff(Date{2027, time.January, 1}))
+	assert.False(t, s.IsOff(Date{2026, time.December, 27}))
+
+	assert.Equal(t, TimeFrames{{600, 720}, {780, 840}}, s.FramesOn(Date{2026, time.November, 2}))
+	assert.Equal(t, TimeFrames{{600, 660}}, s.FramesOn(Date{2026, time.November, 7}))
+	assert.Empty(t, s.FramesOn(Date{2026, time.December, 24}))
+	assert.Len(t, s.FramesOn(Date{2026, time.December, 23}), 1)
+
+	layout := "2006-01-02 15:04"
+	//Thursday 24th is off, next decent day is Monday 28th
+	pTime, err := time.Parse(layout, "2026-12-24 10:00")
+	require.NoError(t, err)
+	assertTime(t, pTime.Add(4*24*time.Hour-time.Hour), s.Next(pTime))
+
+	//Overridden Monday, 09:30 is no longer decent
+	pTime, err = time.Parse(layout, "2026-11-02 09:30")
+	require.NoError(t, err)
+	assertTime(t, pTime.Add(30*time.Minute), s.Next(pTime))
+
+	pTime, err = time.Parse(layout, "2026-11-02 12:30")
+	require.NoError(t, err)
+	assertTime(t, pTime.Add(30*time.Minute), s.Next(pTime))
+
+	//Overridden Saturday gets decent frames
+	pTime, err = time.Parse(layout, "2026-11-07 10:30")
+	require.NoError(t, err)
+	assert.True(t, s.Contains(pTime))
+}
+
+func TestScheduleExceptionsError(t *testing.T) {
+	raw := RawScheduleConfig{
+		Days:     map[time.Weekday]string{time.Monday: "09:00/17:00"},
+		Off:      "2026-12-26..2026-12-24, 2026-13-01",
+		Override: "10:00/12:00, 2026-11-02, 2026-11-03 25:00/26:00",
+	}
+	_, err := NewScheduleFromRaw(&raw)
+	assert.ErrorContains(t, err, "date range is inverse")
+	assert.ErrorContains(t, err, "incorrect date format")
+	assert.ErrorContains(t, err, "expected a date before 10:00/12:00")
+	assert.ErrorContains(t, err, "override 2026-11-02: no frames given")
+	assert.ErrorContains(t, err, "hour out of range")
+}
+
+func TestDaySpans(t *testing.T) {
+	spans := newDaySpans([]DaySpan{
+		{Start: 18 * 3600, End: 19 * 3600},
+		{Start: 9 * 3600, End: 12 * 3600},
+		{Start: 11 * 3600, End: 13 * 3600},
+		{Start: 13 * 3600, End: 14 * 3600},
+		{Start: -3600, End: 3600},
+		{Start: 23 * 3600, End: 25 * 3600},
+		{Start: 20 * 3600, End: 20 * 3600},
+	})
+	assert.Equal(t, DaySpans{
+		{Start: 0, End: 3600},
+		{Start: 9 * 3600, End: 14 * 3600},
+		{Start: 18 * 3600, End: 19 * 3600},
+		{Start: 23 * 3600, End: daySeconds},
+	}, spans)
+
+	assert.True(t, spans.Contains(0))
+	assert.False(t, spans.Contains(3600))
+	assert.True(t, spans.Contains(14*3600-1))
+	assert.False(t, spans.Contains(14*3600))
+	assert.True(t, spans.Contains(daySeconds-1))
+	assert.False(t, DaySpans{}.Contains(0))
+
+	raw := RawScheduleConfig{
+		Days: map[time.Weekday]string{
+			time.Monday: "09:00/17:00",
+		},
+	}
+	s, err := NewScheduleFromRaw(&raw)
+	require.NoError(t, err)
+
+	//The end minute is decent up to its last second
+	assert.True(t, s.Contains(time.Date(2024, 1, 29, 17, 0, 59, 0, time.UTC)))
+	assert.False(t, s.Contains(time.Date(2024, 1, 29, 17, 1, 0, 0, time.UTC)))
+	assert.False(t, s.Contains(time.Date(2024, 1, 29, 8, 59, 59, 0, time.UTC)))
+}
+
+func TestParseFrameFriendly(t *testing.T) {
+	frames := map[string]TimeFrame{
+		"09:00/17:00":  {9 * 60, 17 * 60},
+		"9-17":         {9 * 60, 17 * 60},
+		"09:00-17:00":  {9 * 60, 17 * 60},
+		"9:30/13:00":   {9*60 + 30, 13 * 60},
+		"9am-1pm":      {9 * 60, 13 * 60},
+		"9AM - 1:30PM": {9 * 60, 13*60 + 30},
+		"12am-12pm":    {0, 12 * 60},
+		"10pm-2am":     {22 * 60, 26 * 60},
+	}
+	for raw, expected := range frames {
+		frame, err := parseFrame(raw)
+		assert.NoError(t, err, raw)
+		assert.Equal(t, expected, frame, raw)
+	}
+
+	errors := map[string]string{
+		"9":       `time range format should be 09:00/17:00, 9-17 or 9am-1pm but instead "9" given`,
+		"9-25":    `frame "9-25": hour out of range in "25"`,
+		"13pm-2":  `frame "13pm-2": hour out of range in "13pm"`,
+		"9-17:60": `frame "9-17:60": minute out of range in "17:60"`,
+		"9h-17":   `frame "9h-17": incorrect time format, expected 15:04, 9 or 9am but given "9h"`,
+	}
+	for raw, expected := range errors {
+		_, err := parseFrame(raw)
+		assert.ErrorContains(t, err, expected)
+	}
+}
+
+func TestScheduleFromPlainTextRanges(t *testing.T) {
+	rawC, err := NewScheduleFromPlainText(strings.NewReader(`[decent]
+	Wed = 10-12
+	weekdays = 9-17
+	Sat-Sun = 10am-1pm
+`))
+	require.NoError(t, err)
+	assert.Equal(t, map[time.Weekday]string{
+		time.Monday:    "9-17",
+		time.Tuesday:   "9-17",
+		time.Wednesday: "10-12",
+		time.Thursday:  "9-17",
+		time.Friday:    "9-17",
+		time.Saturday:  "10am-1pm",
+		time.Sunday:    "10am-1pm",
+	}, rawC.Days)
+
+	s, err := NewScheduleFromRaw(rawC)
+	require.NoError(t, err)
+	assert.Equal(t, TimeFrames{{10 * 60, 12 * 60}}, s.Days[time.Wednesday].DecentFrames)
+	assert.Equal(t, TimeFrames{{10 * 60, 13 * 60}}, s.Days[time.Sunday].DecentFrames)
+
+	_, err = NewScheduleFromPlainText(strings.NewReader(`[decent]
+	Mon-Fri = 9-17, 13-25
+	Someday = 9-17
+`))
+	assert.ErrorContains(t, err, `line 2: mon-fri: frame "13-25": hour out of range in "25"`)
+	assert.ErrorContains(t, err, "line 3: invalid day configured, got someday")
+}
@@ -5,3 +5,13 @@ import "time"
 func DayMinute(t time.Time) int {
 	return t.Hour()*60 + t.Minute()
 }
+
+func DaySecond(t time.Time) int {
+	return DayMinute(t)*60 + t.Second()
+}
+
+// SameDate tells if both are the same instant with the same offset, like git
+// writes them, regardless of their location
+func SameDate(a time.Time, b time.Time) bool {
+	return a.Equal(b) && a.Format("-0700") == b.Format("-0700")
+}
@@ -0,0 +1,350 @@
+/* SPDX-License-Identifier: MIT */
+package internal
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/afiestas/git-decent/config"
+)
+
+// Amender moves the dates of a batch of commits to decent times
+type Amender interface {
+	// Amend returns the dates of the batch in the same order, the ones
+	// already decent might be kept
+	Amend(batch Batch) []time.Time
+}
+
+// Commits to amend, oldest first
+type Batch struct {
+	Dates []time.Time
+	// The dates the commits were made at when some were amended before, in
+	// the same order. The real time between commits is taken from them.
+	RealDates []time.Time
+	// The commit before the batch as it is written and as it was made, nil
+	// when unknown. Amended commits are never moved before it.
+	LastDate     *time.Time
+	LastRealDate *time.Time
+}
+
+// The date the commit was made at
+func (b Batch) realDate(k int) time.Time {
+	if k < len(b.RealDates) {
+		return b.RealDates[k]
+	}
+	return b.Dates[k]
+}
+
+// Weeks searched ahead for a start where a whole batch fits
+const searchWeeks = 4
+
+// Least time between spread commits, the ones that don't fit in the day go on
+// to the following frames
+const minSpreadStep = 5 * time.Minute
+
+// Moves tried before giving up on the capacity of the schedule
+const maxPlacements = 1000
+
+// NewAmender returns the amender of the strategy, the one of the schedule
+// when empty and next-frame when none is configured. The capacity, the
+// minimum gap and the jitter of the schedule apply to all of them.
+func NewAmender(strategy string, schedule config.Schedule) (Amender, error) {
+	if strategy == "" {
+		strategy = schedule.Strategy
+	}
+
+	var amender Amender
+	switch strings.ToLower(strategy) {
+	case "", config.NextFrame:
+		amender = &nextFrameAmender{schedule: schedule}
+	case config.PreviousFrame:
+		amender = &previousFrameAmender{schedule: schedule}
+	case config.Spread:
+		amender = &spreadAmender{schedule: schedule}
+	case config.PreserveGaps:
+		amender = &preserveGapsAmender{schedule: schedule}
+	default:
+		return nil, fmt.Errorf("unknown strategy %s, expected one of %s", strategy, strings.Join(config.Strategies, ", "))
+	}
+
+	if schedule.Capacity != nil || schedule.MinGap > 0 {
+		amender = &capacityAmender{Amender: amender, schedule: schedule}
+	}
+	//Seconds are set once the commits are placed
+	if schedule.Jitter > 0 {
+		amender = &jitterAmender{Amender: amender, schedule: schedule}
+	}
+	return amender, nil
+}
+
+// Moves each commit to the next decent time, the ones moved after the
+// previous commit get a few minutes of noise
+type nextFrameAmender struct {
+	schedule config.Schedule
+}
+
+func (a *nextFrameAmender) Amend(batch Batch) []time.Time {
+	amended := make([]time.Time, 0, len(batch.Dates))
+	lastDate, lastRealDate := batch.LastDate, batch.LastRealDate
+	for k, date := range batch.Dates {
+		if k > 0 {
+			previous := batch.realDate(k - 1)
+			lastRealDate = &previous
+		}
+		//Amend takes the time to the previous commit from the date, which
+		//might have been amended before, so the real one moves as much
+		var shifted *time.Time
+		if lastRealDate != nil {
+			s := date.Add(-batch.realDate(k).Sub(*lastRealDate))
+			shifted = &s
+		}
+		amended = append(amended, Amend(date, lastDate, shifted, int(a.schedule.Threshold.Minutes()), a.schedule))
+		lastDate = &amended[k]
+	}
+	return amended
+}
+
+// Moves each commit back to the previous decent time so commits never land
+// in the future. When that goes before the last commit the batch is moved
+// forward like next-frame does.
+type previousFrameAmender struct {
+	schedule config.Schedule
+}
+
+func (a *previousFrameAmender) Amend(batch Batch) []time.Time {
+	amended := make([]time.Time, len(batch.Dates))
+	var bound *time.Time
+	for k := len(batch.Dates) - 1; k >= 0; k-- {
+		date := a.schedule.In(batch.Dates[k])
+		//Commits keep their order, each one before the following
+		if bound != nil && !date.Before(*bound) {
+			date = bound.Add(-time.Duration(noiseMinutes(date, a.schedule.Noise)+1) * time.Minute)
+		}
+		if !a.schedule.Contains(date) {
+			date = a.schedule.Prev(date)
+			if noisy := date.Add(-time.Duration(noiseMinutes(date, a.schedule.Noise)) * time.Minute); a.schedule.Contains(noisy) {
+				date = noisy
+			}
+		}
+		if batch.LastDate != nil && !date.After(*batch.LastDate) {
+			return (&nextFrameAmender{schedule: a.schedule}).Amend(batch)
+		}
+		amended[k] = date
+		bound = &amended[k]
+	}
+	return amended
+}
+
+// Spreads the batch evenly over the decent time left in the day of the first
+// decent time, commits that don't fit continue in the following frames
+type spreadAmender struct {
+	schedule config.Schedule
+}
+
+func (a *spreadAmender) Amend(batch Batch) []time.Time {
+	if isDecent(a.schedule, batch) {
+		return batch.Dates
+	}
+
+	start := a.schedule.Next(earliest(a.schedule, batch))
+	windows := a.schedule.Windows(start, start.AddDate(0, 0, 7*searchWeeks))
+	if len(windows) == 0 {
+		return batch.Dates
+	}
+
+	y, m, d := start.Date()
+	endOfDay := time.Date(y, m, d+1, 0, 0, 0, 0, start.Location())
+	decent := time.Duration(0)
+	for _, window := range a.schedule.Windows(start, endOfDay) {
+		decent += window.End.Sub(window.Start)
+	}
+	step := max((decent / time.Duration(len(batch.Dates))).Truncate(time.Minute), minSpreadStep, a.schedule.MinGap)
+
+	amended := make([]time.Time, 0, len(batch.Dates))
+	for k := range batch.Dates {
+		amended = append(amended, walkWindows(a.schedule, windows, time.Duration(k)*step))
+	}
+	return amended
+}
+
+// Shifts the whole batch to the first decent time where all of it is decent,
+// keeping the real spacing between commits. When no such time is found the batch
+// is amended like next-frame does.
+type preserveGapsAmender struct {
+	schedule config.Schedule
+}
+
+func (a *preserveGapsAmender) Amend(batch Batch) []time.Time {
+	if isDecent(a.schedule, batch) {
+		return batch.Dates
+	}
+
+	first := a.schedule.In(batch.realDate(0))
+	from := a.schedule.Next(earliest(a.schedule, batch))
+	starts := []time.Time{from}
+	for _, window := range a.schedule.Windows(from, from.AddDate(0, 0, 7*searchWeeks)) {
+		if window.Start.After(from) {
+			starts = append(starts, window.Start)
+		}
+	}
+
+	for _, start := range starts {
+		shift := start.Sub(first)
+		amended := make([]time.Time, 0, len(batch.Dates))
+		for k := range batch.Dates {
+			amended = append(amended, a.schedule.In(batch.realDate(k).Add(shift)))
+		}
+		if isDecent(a.schedule, Batch{Dates: amended, LastDate: batch.LastDate}) {
+			return amended
+		}
+	}
+	return (&nextFrameAmender{schedule: a.schedule}).Amend(batch)
+}
+
+// All the commits are decent and after the previous ones
+func isDecent(schedule config.Schedule, batch Batch) bool {
+	last := batch.LastDate
+	for k, date := range batch.Dates {
+		if !schedule.Contains(date) || (last != nil && date.Before(*last)) {
+			return false
+		}
+		last = &batch.Dates[k]
+	}
+	return true
+}
+
+// The first date the batch can start at, after the last commit
+func earliest(schedule config.Schedule, batch Batch) time.Time {
+	first := schedule.In(batch.Dates[0])
+	if batch.LastDate != nil && !first.After(*batch.LastDate) {
+		return schedule.In(batch.LastDate.Add(time.Minute))
+	}
+	return first
+}
+
+// The time at the given amount of decent time from the first window, going
+// on to the following windows of the schedule when they are not enough
+func walkWindows(schedule config.Schedule, windows []config.Interval, offset time.Duration) time.Time {
+	for {
+		for _, window := range windows {
+			if length := window.End.Sub(window.Start); offset >= length {
+				offset -= length
+				continue
+			}
+			return window.Start.Add(offset)
+		}
+
+		end := windows[len(windows)-1].End
+		windows = schedule.Windows(end, end.AddDate(0, 0, 7*searchWeeks))
+		if len(windows) == 0 {
+			//No decent time left, the last decent minute
+			return end.Add(-time.Minute)
+		}
+	}
+}
+
+// Moves the amended commits over the capacity of their hour or frame, or
+// closer than the minimum gap to the previous one, to the following decent
+// time with room for them plus some noise. Commits kept by the strategy stay.
+type capacityAmender struct {
+	Amender
+	schedule config.Schedule
+}
+
+func (a *capacityAmender) Amend(batch Batch) []time.Time {
+	amended := a.Amender.Amend(batch)
+	counts := map[int64]int{}
+	last := batch.LastDate
+	if last != nil {
+		start, _ := a.bucket(*last)
+		counts[start.Unix()]++
+	}
+
+	for k, date := range amended {
+		//Commits the strategy kept only move to stay after the previous one
+		moved := !date.Equal(batch.Dates[k])
+		if last != nil && date.Before(*last) {
+			moved = true
+		}
+		if moved {
+			placed := date
+			if last != nil && placed.Before(last.Add(a.schedule.MinGap)) {
+				placed = last.Add(a.schedule.MinGap)
+			}
+			placed = a.place(placed, counts)
+			//Pushed to the start of an hour, a frame or the gap, the noise
+			//keeps them off a grid
+			if !placed.Equal(date) {
+				placed = a.withNoise(placed)
+			}
+			date = placed
+		}
+
+		start, _ := a.bucket(date)
+		counts[start.Unix()]++
+		amended[k] = date
+		last = &amended[k]
+	}
+	return amended
+}
+
+// The first decent time from the date in an hour or frame with room, counts
+// are by the unix time of their start
+func (a *capacityAmender) place(date time.Time, counts map[int64]int) time.Time {
+	for n := 0; n < maxPlacements; n++ {
+		date = a.schedule.Next(date)
+		start, end := a.bucket(date)
+		if a.schedule.Capacity == nil || counts[start.Unix()] < a.schedule.Capacity.Commits {
+			return date
+		}
+		date = end
+	}
+	return date
+}
+
+// The date with noise when it stays decent and in its hour or frame
+func (a *capacityAmender) withNoise(date time.Time) time.Time {
+	noisy := date.Add(time.Duration(noiseMinutes(date, a.schedule.Noise)) * time.Minute)
+	if _, end := a.bucket(date); noisy.Before(end) && a.schedule.Contains(noisy) {
+		return noisy
+	}
+	return date
+}
+
+// The hour or the decent frame of the date, by its start and end
+func (a *capacityAmender) bucket(date time.Time) (time.Time, time.Time) {
+	date = a.schedule.In(date)
+	if a.schedule.Capacity != nil && a.schedule.Capacity.PerFrame {
+		for _, window := range a.schedule.Windows(date.AddDate(0, 0, -1), date.AddDate(0, 0, 1)) {
+			if !date.Before(window.Start) && date.Before(window.End) {
+				return window.Start, window.End
+			}
+		}
+	}
+	y, m, d := date.Date()
+	hour := time.Date(y, m, d, date.Hour(), 0, 0, 0, date.Location())
+	return hour, hour.Add(time.Hour)
+}
+
+// Sets random seconds on the commits moved, keeping them in their minute and
+// after the previous one
+type jitterAmender struct {
+	Amender
+	schedule config.Schedule
+}
+
+func (a *jitterAmender) Amend(batch Batch) []time.Time {
+	amended := a.Amender.Amend(batch)
+	for k, date := range amended {
+		if date.Equal(batch.Dates[k]) {
+			continue
+		}
+		date = date.Add(-time.Duration(date.Second())*time.Second + randomSeconds(a.schedule.Jitter))
+		if k > 0 && date.Before(amended[k-1]) {
+			date = amended[k-1]
+		}
+		amended[k] = date
+	}
+	return amended
+}
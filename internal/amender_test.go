@@ -0,0 +1,374 @@
+/* SPDX-License-Identifier: MIT */
+package internal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/afiestas/git-decent/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func amenderFor(t *testing.T, strategy string, days map[time.Weekday]string) Amender {
+	schedule, err := config.NewScheduleFromRaw(&config.RawScheduleConfig{Days: days})
+	require.NoError(t, err)
+	amender, err := NewAmender(strategy, schedule)
+	require.NoError(t, err)
+	return amender
+}
+
+func parseDates(t *testing.T, dates ...string) []time.Time {
+	parsed := []time.Time{}
+	for _, date := range dates {
+		p, err := time.Parse(time.RFC1123Z, date)
+		require.NoError(t, err)
+		parsed = append(parsed, p)
+	}
+	return parsed
+}
+
+func assertDates(t *testing.T, expected []time.Time, dates []time.Time) {
+	require.Len(t, dates, len(expected))
+	for k := range expected {
+		assert.Equalf(t, expected[k].Format(time.RFC1123Z), dates[k].Format(time.RFC1123Z), "date %d", k)
+	}
+}
+
+func TestNewAmender(t *testing.T) {
+	schedule, err := config.NewScheduleFromRaw(&config.RawScheduleConfig{
+		Days:     map[time.Weekday]string{time.Monday: "09:00/17:00"},
+		Strategy: "spread",
+	})
+	require.NoError(t, err)
+
+	amender, err := NewAmender("", schedule)
+	require.NoError(t, err)
+	assert.IsType(t, &spreadAmender{}, amender)
+
+	amender, err = NewAmender("previous-frame", schedule)
+	require.NoError(t, err)
+	assert.IsType(t, &previousFrameAmender{}, amender)
+
+	_, err = NewAmender("random", schedule)
+	assert.ErrorContains(t, err, "unknown strategy random")
+}
+
+func TestNextFrameAmender(t *testing.T) {
+	testRandom = true
+	defer func() {
+		testRandom = false
+	}()
+
+	amender := amenderFor(t, config.NextFrame, map[time.Weekday]string{time.Monday: "09:00/17:00"})
+	dates := parseDates(t, "Sun, 28 Jan 2024 18:30:00 +0200", "Sun, 28 Jan 2024 19:10:00 +0200", "Sun, 28 Jan 2024 23:59:00 +0200")
+	assertDates(t,
+		parseDates(t, "Mon, 29 Jan 2024 09:00:00 +0200", "Mon, 29 Jan 2024 09:05:00 +0200", "Mon, 29 Jan 2024 09:14:00 +0200"),
+		amender.Amend(Batch{Dates: dates}),
+	)
+
+	//Amended again, the time between the commits is the real one
+	schedule, err := config.NewScheduleFromRaw(&config.RawScheduleConfig{
+		Days:      map[time.Weekday]string{time.Monday: "09:00/17:00"},
+		Threshold: "120",
+	})
+	require.NoError(t, err)
+	amender, err = NewAmender(config.NextFrame, schedule)
+	require.NoError(t, err)
+	assertDates(t,
+		parseDates(t, "Mon, 29 Jan 2024 09:00:00 +0200", "Mon, 29 Jan 2024 09:40:00 +0200", "Mon, 29 Jan 2024 10:10:00 +0200"),
+		amender.Amend(Batch{
+			Dates:     parseDates(t, "Mon, 29 Jan 2024 09:00:00 +0200", "Mon, 29 Jan 2024 09:40:00 +0200", "Sun, 28 Jan 2024 19:40:00 +0200"),
+			RealDates: parseDates(t, "Sun, 28 Jan 2024 18:30:00 +0200", "Sun, 28 Jan 2024 19:10:00 +0200", "Sun, 28 Jan 2024 19:40:00 +0200"),
+		}),
+	)
+}
+
+func TestPreviousFrameAmender(t *testing.T) {
+	testRandom = true
+	defer func() {
+		testRandom = false
+	}()
+
+	amender := amenderFor(t, config.PreviousFrame, map[time.Weekday]string{
+		time.Monday: "09:00/17:00",
+		time.Friday: "09:00/17:00",
+	})
+	dates := parseDates(t, "Sun, 28 Jan 2024 18:30:00 +0200", "Sun, 28 Jan 2024 19:35:00 +0200")
+	assertDates(t,
+		parseDates(t, "Fri, 26 Jan 2024 16:49:00 +0200", "Fri, 26 Jan 2024 16:55:00 +0200"),
+		amender.Amend(Batch{Dates: dates}),
+	)
+
+	//Decent commits are kept
+	decent := parseDates(t, "Mon, 29 Jan 2024 10:00:00 +0200")
+	assertDates(t, decent, amender.Amend(Batch{Dates: decent}))
+
+	//Moving them back would put them before the last commit
+	last := parseDates(t, "Fri, 26 Jan 2024 16:50:00 +0200")[0]
+	assertDates(t,
+		parseDates(t, "Mon, 29 Jan 2024 09:05:00 +0200", "Mon, 29 Jan 2024 09:10:00 +0200"),
+		amender.Amend(Batch{Dates: dates, LastDate: &last, LastRealDate: &last}),
+	)
+}
+
+func TestBatchAfterParent(t *testing.T) {
+	testRandom = true
+	defer func() {
+		testRandom = false
+	}()
+
+	dates := parseDates(t, "Mon, 29 Jan 2024 16:58:00 +0100", "Mon, 29 Jan 2024 20:00:00 +0100")
+	repo := NewRepositoryBuilder(t).WithCommitsWithDates(dates).MustBuild()
+	log, err := repo.LogWithRevision("-1")
+	require.NoError(t, err)
+
+	batch, err := repo.BatchOf(log)
+	require.NoError(t, err)
+	require.NotNil(t, batch.LastDate)
+	assert.True(t, dates[0].Equal(*batch.LastDate))
+	assert.True(t, dates[0].Equal(*batch.LastRealDate))
+	assertDates(t, dates[1:], batch.RealDates)
+
+	amender := amenderFor(t, config.PreviousFrame, map[time.Weekday]string{
+		time.Monday:  "09:00/17:00",
+		time.Tuesday: "09:00/17:00",
+	})
+	//Back from 20:00 it would land at 16:55, before the parent
+	assertDates(t, parseDates(t, "Mon, 29 Jan 2024 16:55:00 +0100"), amender.Amend(Batch{Dates: batch.Dates}))
+	assertDates(t, parseDates(t, "Tue, 30 Jan 2024 09:05:00 +0100"), amender.Amend(batch))
+}
+
+func TestSpreadAmender(t *testing.T) {
+	amender := amenderFor(t, config.Spread, map[time.Weekday]string{
+		time.Monday:  "09:00/17:00",
+		time.Tuesday: "09:00/17:00",
+	})
+	dates := parseDates(t,
+		"Sat, 27 Jan 2024 10:00:00 +0200",
+		"Sat, 27 Jan 2024 10:10:00 +0200",
+		"Sun, 28 Jan 2024 11:00:00 +0200",
+		"Sun, 28 Jan 2024 11:01:00 +0200",
+	)
+	assertDates(t,
+		parseDates(t,
+			"Mon, 29 Jan 2024 09:00:00 +0200",
+			"Mon, 29 Jan 2024 11:00:00 +0200",
+			"Mon, 29 Jan 2024 13:00:00 +0200",
+			"Mon, 29 Jan 2024 15:00:00 +0200",
+		),
+		amender.Amend(Batch{Dates: dates}),
+	)
+
+	//Starting late in the day the batch is spread over what is left of it
+	last := parseDates(t, "Mon, 29 Jan 2024 16:29:00 +0200")[0]
+	assertDates(t,
+		parseDates(t,
+			"Mon, 29 Jan 2024 16:30:00 +0200",
+			"Mon, 29 Jan 2024 16:37:00 +0200",
+			"Mon, 29 Jan 2024 16:44:00 +0200",
+			"Mon, 29 Jan 2024 16:51:00 +0200",
+		),
+		amender.Amend(Batch{Dates: dates, LastDate: &last}),
+	)
+
+	//Too late for all of them, the rest go on to the next day
+	last = parseDates(t, "Mon, 29 Jan 2024 16:49:00 +0200")[0]
+	assertDates(t,
+		parseDates(t,
+			"Mon, 29 Jan 2024 16:50:00 +0200",
+			"Mon, 29 Jan 2024 16:55:00 +0200",
+			"Mon, 29 Jan 2024 17:00:00 +0200",
+			"Tue, 30 Jan 2024 09:04:00 +0200",
+		),
+		amender.Amend(Batch{Dates: dates, LastDate: &last}),
+	)
+
+	decent := parseDates(t, "Mon, 29 Jan 2024 10:00:00 +0200", "Mon, 29 Jan 2024 10:30:00 +0200")
+	assertDates(t, decent, amender.Amend(Batch{Dates: decent}))
+}
+
+func TestPreserveGapsAmender(t *testing.T) {
+	testRandom = true
+	defer func() {
+		testRandom = false
+	}()
+
+	amender := amenderFor(t, config.PreserveGaps, map[time.Weekday]string{
+		time.Monday:  "09:00/17:00",
+		time.Tuesday: "09:00/17:00",
+	})
+	dates := parseDates(t, "Sun, 28 Jan 2024 18:30:00 +0200", "Sun, 28 Jan 2024 19:35:00 +0200", "Sun, 28 Jan 2024 23:59:00 +0200")
+	assertDates(t,
+		parseDates(t, "Mon, 29 Jan 2024 09:00:00 +0200", "Mon, 29 Jan 2024 10:05:00 +0200", "Mon, 29 Jan 2024 14:29:00 +0200"),
+		amender.Amend(Batch{Dates: dates}),
+	)
+
+	//The batch doesn't fit in what is left of Monday
+	last := parseDates(t, "Mon, 29 Jan 2024 12:00:00 +0200")[0]
+	assertDates(t,
+		parseDates(t, "Tue, 30 Jan 2024 09:00:00 +0200", "Tue, 30 Jan 2024 10:05:00 +0200", "Tue, 30 Jan 2024 14:29:00 +0200"),
+		amender.Amend(Batch{Dates: dates, LastDate: &last}),
+	)
+
+	//The first two were amended before, the gaps are the real ones
+	amended := parseDates(t, "Mon, 29 Jan 2024 09:00:00 +0200", "Mon, 29 Jan 2024 09:05:00 +0200", dates[2].Format(time.RFC1123Z))
+	assertDates(t,
+		parseDates(t, "Mon, 29 Jan 2024 09:00:00 +0200", "Mon, 29 Jan 2024 10:05:00 +0200", "Mon, 29 Jan 2024 14:29:00 +0200"),
+		amender.Amend(Batch{Dates: amended, RealDates: dates}),
+	)
+
+	//Longer than any frame, amended like next-frame
+	long := parseDates(t, "Sat, 27 Jan 2024 08:00:00 +0200", "Sat, 27 Jan 2024 20:00:00 +0200")
+	assertDates(t,
+		parseDates(t, "Mon, 29 Jan 2024 09:00:00 +0200", "Mon, 29 Jan 2024 09:05:00 +0200"),
+		amender.Amend(Batch{Dates: long}),
+	)
+}
+
+func TestCapacityAmender(t *testing.T) {
+	testRandom = true
+	defer func() {
+		testRandom = false
+	}()
+
+	schedule, err := config.NewScheduleFromRaw(&config.RawScheduleConfig{
+		Days: map[time.Weekday]string{
+			time.Monday:  "09:00/17:00",
+			time.Tuesday: "09:00/17:00",
+		},
+		Capacity: "3/hour",
+		MinGap:   "10",
+	})
+	require.NoError(t, err)
+	amender, err := NewAmender("", schedule)
+	require.NoError(t, err)
+
+	//A weekend of commits doesn't pile up at the start of Monday, the ones
+	//pushed get noise again
+	weekend := parseDates(t, "Sat, 27 Jan 2024 10:00:00 +0200")
+	for k := 1; k < 30; k++ {
+		weekend = append(weekend, weekend[0].Add(time.Duration(k)*time.Minute))
+	}
+	amended := amender.Amend(Batch{Dates: weekend})
+	assertDates(t,
+		parseDates(t,
+			"Mon, 29 Jan 2024 09:00:00 +0200",
+			"Mon, 29 Jan 2024 09:15:00 +0200",
+			"Mon, 29 Jan 2024 09:30:00 +0200",
+			"Mon, 29 Jan 2024 10:05:00 +0200",
+		),
+		amended[:4],
+	)
+	perHour := map[int64]int{}
+	for k, date := range amended {
+		assert.True(t, schedule.Contains(date))
+		perHour[date.Truncate(time.Hour).Unix()]++
+		if k > 0 {
+			assert.GreaterOrEqual(t, date.Sub(amended[k-1]), 10*time.Minute)
+		}
+	}
+	for _, count := range perHour {
+		assert.LessOrEqual(t, count, 3)
+	}
+	assertDates(t,
+		parseDates(t, "Mon, 29 Jan 2024 17:00:00 +0200", "Tue, 30 Jan 2024 09:05:00 +0200"),
+		amended[24:26],
+	)
+
+	//Decent commits are kept
+	decent := parseDates(t, "Mon, 29 Jan 2024 10:00:00 +0200", "Mon, 29 Jan 2024 10:01:00 +0200", "Mon, 29 Jan 2024 10:02:00 +0200", "Mon, 29 Jan 2024 10:03:00 +0200")
+	assertDates(t, decent, amender.Amend(Batch{Dates: decent}))
+}
+
+func TestCapacityPerFrame(t *testing.T) {
+	testRandom = true
+	defer func() {
+		testRandom = false
+	}()
+
+	schedule, err := config.NewScheduleFromRaw(&config.RawScheduleConfig{
+		Days:     map[time.Weekday]string{time.Monday: "09:00/12:00, 13:00/17:00"},
+		Strategy: config.Spread,
+		Capacity: "2/frame",
+	})
+	require.NoError(t, err)
+	amender, err := NewAmender("", schedule)
+	require.NoError(t, err)
+
+	dates := parseDates(t,
+		"Sat, 27 Jan 2024 10:00:00 +0200",
+		"Sat, 27 Jan 2024 11:00:00 +0200",
+		"Sat, 27 Jan 2024 12:00:00 +0200",
+		"Sat, 27 Jan 2024 13:00:00 +0200",
+		"Sat, 27 Jan 2024 14:00:00 +0200",
+	)
+	assertDates(t,
+		parseDates(t,
+			"Mon, 29 Jan 2024 09:00:00 +0200",
+			"Mon, 29 Jan 2024 10:24:00 +0200",
+			"Mon, 29 Jan 2024 13:05:00 +0200",
+			"Mon, 29 Jan 2024 14:11:00 +0200",
+			"Mon, 05 Feb 2024 09:05:00 +0200",
+		),
+		amender.Amend(Batch{Dates: dates}),
+	)
+}
+
+func TestWalkWindows(t *testing.T) {
+	schedule, err := config.NewScheduleFromRaw(&config.RawScheduleConfig{
+		Days: map[time.Weekday]string{time.Monday: "09:00/10:00"},
+	})
+	require.NoError(t, err)
+
+	start := parseDates(t, "Mon, 29 Jan 2024 09:00:00 +0200")[0]
+	windows := schedule.Windows(start, start.AddDate(0, 0, 1))
+	require.Len(t, windows, 1)
+
+	//Past the windows it goes on to the next ones of the schedule
+	assertDates(t,
+		parseDates(t, "Mon, 29 Jan 2024 09:30:00 +0200", "Mon, 05 Feb 2024 09:30:00 +0200"),
+		[]time.Time{walkWindows(schedule, windows, 30*time.Minute), walkWindows(schedule, windows, windows[0].End.Sub(windows[0].Start)+30*time.Minute)},
+	)
+}
+
+func TestAmenderSettings(t *testing.T) {
+	testRandom = true
+	defer func() {
+		testRandom = false
+	}()
+
+	schedule, err := config.NewScheduleFromRaw(&config.RawScheduleConfig{
+		Days:      map[time.Weekday]string{time.Monday: "09:00/17:00"},
+		Threshold: "60",
+		Noise:     "10-20",
+		Jitter:    "30",
+	})
+	require.NoError(t, err)
+	amender, err := NewAmender("", schedule)
+	require.NoError(t, err)
+
+	//The first interval is kept, the second is over the threshold
+	dates := parseDates(t, "Sun, 28 Jan 2024 18:30:00 +0200", "Sun, 28 Jan 2024 19:05:00 +0200", "Sun, 28 Jan 2024 23:59:00 +0200")
+	assertDates(t,
+		parseDates(t, "Mon, 29 Jan 2024 09:00:15 +0200", "Mon, 29 Jan 2024 09:35:15 +0200", "Mon, 29 Jan 2024 09:50:15 +0200"),
+		amender.Amend(Batch{Dates: dates}),
+	)
+}
+
+func TestNoiseMinutes(t *testing.T) {
+	date := parseDates(t, "Sun, 28 Jan 2024 18:37:00 +0200")[0]
+	assert.Equal(t, 7, noiseMinutes(date, nil))
+
+	for _, noise := range []config.Noise{{Min: 2, Max: 15}, {Min: 2, Max: 15, Normal: true}, {Min: 4, Max: 4}} {
+		for n := 0; n < 200; n++ {
+			minutes := noiseMinutes(date, &noise)
+			assert.GreaterOrEqual(t, minutes, noise.Min)
+			assert.LessOrEqual(t, minutes, noise.Max)
+		}
+	}
+
+	for n := 0; n < 200; n++ {
+		assert.LessOrEqual(t, randomSeconds(30*time.Second), 30*time.Second)
+	}
+}
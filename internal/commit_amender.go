@@ -13,23 +13,21 @@ func Amend(date time.Time, lastDate *time.Time, lastRealDate *time.Time, thresho
 	db := utils.DebugBlock{Title: "⏰ Amending: " + date.String()}
 	db.AddLine("Threshold", fmt.Sprint(threshold))
 
+	//Frames are read in the schedule timezone, so is the amended date written
+	date = schedule.In(date)
+
 	//When it is the first commit, just look for the closest decent frame
 	if lastDate == nil {
 		db.AddLine("LastDate:", "None")
-		_, dMin := schedule.ClosestDecentMinute(date)
-		db.AddLine("ClosestDecentMinute:", fmt.Sprint(dMin))
+		next := schedule.Next(date)
+		db.AddLine("Next decent time:", next.String())
 		db.Print()
-		return date.Add(time.Duration(dMin) * time.Minute)
+		return next
 	}
 
 	db.AddLine("LastDate:", lastDate.String())
 
-	//Instead of generating random numbers we use the already humandly random generated
-	//minute when the commit was created
-	noise := date.Minute() % 10
-	if noise == 0 {
-		noise = randomMinute()
-	}
+	noise := noiseMinutes(date, schedule.Noise)
 
 	interval := 0
 	if lastRealDate != nil {
@@ -53,12 +51,12 @@ func Amend(date time.Time, lastDate *time.Time, lastRealDate *time.Time, thresho
 		date = date.Add(time.Duration(interval) * time.Minute)
 	}
 
-	_, dMin := schedule.ClosestDecentMinute(date)
-	if dMin > 0 {
+	if !schedule.Contains(date) {
 		//Add the noise to avoid many commits with time 0
-		db.AddLine("Moved to a different frame", fmt.Sprint(dMin))
+		next := schedule.Next(date)
+		db.AddLine("Moved to a different frame", next.String())
 		db.AddLine("AddedNoise:", fmt.Sprint(noise))
-		date = date.Add(time.Duration(dMin+noise) * time.Minute)
+		date = next.Add(time.Duration(noise) * time.Minute)
 	}
 
 	db.AddLine("Final date", date.String())
@@ -164,3 +164,138 @@ func TestAmendCommitsKeepInterval(t *testing.T) {
 		})
 	}
 }
+
+func TestAmendWithTimezone(t *testing.T) {
+	testRandom = true
+	defer func() {
+		testRandom = false
+	}()
+
+	schedule, err := config.NewScheduleFromRaw(&config.RawScheduleConfig{
+		Days:     map[time.Weekday]string{time.Monday: "09:00/17:00"},
+		Timezone: "Europe/Berlin",
+	})
+	require.NoError(t, err)
+
+	//Commit done while travelling, 10:00 in New York is 16:00 in Berlin
+	travelling, err := time.Parse(time.RFC1123Z, "Mon, 29 Jan 2024 10:00:00 -0500")
+	require.NoError(t, err)
+	amended := Amend(travelling, nil, nil, 0, schedule)
+	assert.Equal(t, "Mon, 29 Jan 2024 16:00:00 +0100", amended.Format(time.RFC1123Z))
+
+	//20:00 in New York is 02:00 in Berlin, moved to the next Monday frame
+	travelling, err = time.Parse(time.RFC1123Z, "Mon, 29 Jan 2024 20:00:00 -0500")
+	require.NoError(t, err)
+	amended = Amend(travelling, nil, nil, 0, schedule)
+	assert.Equal(t, "Mon, 05 Feb 2024 09:00:00 +0100", amended.Format(time.RFC1123Z))
+
+	//Already in a frame it moves to the location of the timezone but keeps its date
+	decent, err := time.Parse(time.RFC1123Z, "Mon, 29 Jan 2024 10:00:00 +0100")
+	require.NoError(t, err)
+	amender, err := NewAmender("", schedule)
+	require.NoError(t, err)
+	for _, amended := range []time.Time{Amend(decent, nil, nil, 0, schedule), amender.Amend(Batch{Dates: []time.Time{decent}})[0]} {
+		assert.NotSame(t, decent.Location(), amended.Location())
+		assert.True(t, config.SameDate(decent, amended))
+	}
+	assert.False(t, config.SameDate(decent, decent.In(time.UTC)), "the offset is part of the date")
+}
+
+func TestAmendOnDayOff(t *testing.T) {
+	testRandom = true
+	defer func() {
+		testRandom = false
+	}()
+
+	schedule, err := config.NewScheduleFromRaw(&config.RawScheduleConfig{
+		Days: map[time.Weekday]string{
+			time.Monday:  "09:00/17:00",
+			time.Tuesday: "09:00/17:00",
+		},
+		Off: "2024-01-29",
+	})
+	require.NoError(t, err)
+
+	holiday, err := time.Parse(time.RFC1123Z, "Mon, 29 Jan 2024 10:00:00 +0100")
+	require.NoError(t, err)
+	amended := Amend(holiday, nil, nil, 0, schedule)
+	assert.Equal(t, "Tue, 30 Jan 2024 09:00:00 +0100", amended.Format(time.RFC1123Z))
+}
+
+func TestAmendAcrossDST(t *testing.T) {
+	testRandom = true
+	defer func() {
+		testRandom = false
+	}()
+
+	transitions := []struct {
+		zone     string
+		saturday string
+		monday   string
+	}{
+		{"Europe/Berlin", "2026-03-28", "Mon, 30 Mar 2026 09:00:00 +0200"},
+		{"Europe/Berlin", "2026-10-24", "Mon, 26 Oct 2026 09:00:00 +0100"},
+		{"America/New_York", "2026-03-07", "Mon, 09 Mar 2026 09:00:00 -0400"},
+		{"America/New_York", "2026-10-31", "Mon, 02 Nov 2026 09:00:00 -0500"},
+		{"Australia/Sydney", "2026-04-04", "Mon, 06 Apr 2026 09:00:00 +1000"},
+		{"Australia/Sydney", "2026-10-03", "Mon, 05 Oct 2026 09:00:00 +1100"},
+		{"Australia/Lord_Howe", "2026-04-04", "Mon, 06 Apr 2026 09:00:00 +1030"},
+		{"Australia/Lord_Howe", "2026-10-03", "Mon, 05 Oct 2026 09:00:00 +1100"},
+	}
+
+	for _, tc := range transitions {
+		loc, err := time.LoadLocation(tc.zone)
+		require.NoError(t, err)
+
+		//Without Timezone the frames are read in the zone of the commits
+		for _, timezone := range []string{tc.zone, ""} {
+			t.Run(tc.zone+" "+tc.saturday+" "+timezone, func(t *testing.T) {
+				schedule, err := config.NewScheduleFromRaw(&config.RawScheduleConfig{
+					Days:     map[time.Weekday]string{time.Monday: "09:00/17:00"},
+					Timezone: timezone,
+				})
+				require.NoError(t, err)
+
+				saturday, err := time.ParseInLocation("2006-01-02 15:04", tc.saturday+" 20:00", loc)
+				require.NoError(t, err)
+
+				amended := Amend(saturday, nil, nil, 0, schedule)
+				assert.Equal(t, tc.monday, amended.Format(time.RFC1123Z))
+				assert.True(t, schedule.Contains(amended))
+
+				//Following commits keep their interval and stay in the frame
+				next := saturday.Add(time.Hour)
+				amendedNext := Amend(next, &amended, &saturday, 120, schedule)
+				assert.True(t, schedule.Contains(amendedNext))
+				assert.True(t, amended.Add(time.Hour).Equal(amendedNext))
+			})
+		}
+	}
+}
+
+func TestAmendLogAcrossDSTWithoutTimezone(t *testing.T) {
+	testRandom = true
+	local := time.Local
+	defer func() {
+		testRandom = false
+		time.Local = local
+	}()
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+	time.Local = berlin
+
+	//Made in the local timezone, the frames follow its DST change
+	saturday := time.Date(2024, 03, 30, 23, 0, 0, 0, time.FixedZone("", 60*60))
+	repo := NewRepositoryBuilder(t).WithCommitsWithDates([]time.Time{saturday}).MustBuild()
+	log, err := repo.Log()
+	require.NoError(t, err)
+	require.Len(t, log, 1)
+
+	schedule, err := config.NewScheduleFromRaw(&config.RawScheduleConfig{
+		Days: map[time.Weekday]string{time.Monday: "09:00/17:00"},
+	})
+	require.NoError(t, err)
+	amended := Amend(log[0].Date, nil, nil, 0, schedule)
+	assert.Equal(t, "Mon, 01 Apr 2024 09:00:00 +0200", amended.Format(time.RFC1123Z))
+	assert.True(t, schedule.Contains(amended))
+}
@@ -4,9 +4,11 @@ package internal
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -34,8 +36,12 @@ type Commit struct {
 	Hash    string
 	Message string
 	Date    time.Time
-	Author  string
-	Files   []string
+	// Committer date to amend with, when zero it keeps its distance to Date
+	CommitterDate time.Time
+	Author        string
+	Files         []string
+	// Hashes of the parents, several for merges
+	Parents []string
 	Prev    *Commit
 	Next    *Commit
 }
@@ -98,9 +104,18 @@ func newGitRepo(dir string, verbose bool) (*GitRepo, error) {
 }
 
 func (r *GitRepo) commandWithEnv(env []string, arg ...string) (string, error) {
+	return r.run(env, nil, arg...)
+}
+
+// The input is given to the command in its stdin
+func (r *GitRepo) commandWithInput(input string, arg ...string) (string, error) {
+	return r.run([]string{}, strings.NewReader(input), arg...)
+}
 
+func (r *GitRepo) run(env []string, stdin io.Reader, arg ...string) (string, error) {
 	cmd := exec.Command(g, arg...)
 	cmd.Dir = r.Dir
+	cmd.Stdin = stdin
 	db := utils.DebugBlock{Title: fmt.Sprintf("⚙️ %s", cmd.String())}
 	db.AddLine("Cmd", cmd.String())
 
@@ -211,6 +226,12 @@ func (r *GitRepo) Origin() (string, error) {
 	return strings.TrimSpace(string(output)), nil
 }
 
+// TopLevel returns the root of the working tree
+func (r *GitRepo) TopLevel() (string, error) {
+	out, err := r.command("rev-parse", "--show-toplevel")
+	return strings.TrimSpace(out), err
+}
+
 func (r *GitRepo) CurrentBranch() string {
 	output, err := r.command("rev-parse", "--abbrev-ref", "HEAD")
 	if err != nil {
@@ -239,6 +260,25 @@ func (r *GitRepo) State() RepoState {
 	return state
 }
 
+// BranchRemote returns the remote the branch tracks, origin when it doesn't
+// track any
+func (r *GitRepo) BranchRemote(branch string) string {
+	out, err := r.command("config", "--get", fmt.Sprintf("branch.%s.remote", branch))
+	if err != nil || strings.TrimSpace(out) == "" {
+		return "origin"
+	}
+	return strings.TrimSpace(out)
+}
+
+func (r *GitRepo) RemoteURL(remote string) (string, error) {
+	output, err := r.command("remote", "get-url", remote)
+	if err != nil {
+		return "", fmt.Errorf("couldn't get the url of %s %w", remote, err)
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
 func (r *GitRepo) BranchUpstream(branch string) string {
 	out, err := r.command("rev-parse", "--abbrev-ref", fmt.Sprintf("%s@{u}", branch))
 	if err != nil {
@@ -286,11 +326,85 @@ func (r *GitRepo) GetHook(name string) (string, error) {
 	return string(content), err
 }
 
+// The git config file read or written: --local, --global or --file <path>
+type ConfigScope []string
+
+var (
+	LocalConfig  = ConfigScope{"--local"}
+	GlobalConfig = ConfigScope{"--global"}
+)
+
+func FileConfig(path string) ConfigScope {
+	return ConfigScope{"--file", path}
+}
+
 func (r *GitRepo) SetConfig(key string, value string) error {
-	_, err := r.command("config", "--local", key, value)
+	return r.SetConfigIn(LocalConfig, key, value)
+}
+
+func (r *GitRepo) SetConfigIn(scope ConfigScope, key string, value string) error {
+	args := append([]string{"config"}, scope...)
+	_, err := r.command(append(args, key, value)...)
+	return err
+}
+
+func (r *GitRepo) UnsetConfigIn(scope ConfigScope, key string) error {
+	args := append([]string{"config"}, scope...)
+	_, err := r.command(append(args, "--unset-all", key)...)
 	return err
 }
 
+// ConfigFile returns the path of the file behind the scope, it might not exist yet
+func (r *GitRepo) ConfigFile(scope ConfigScope) (string, error) {
+	switch {
+	case len(scope) == 2 && scope[0] == "--file":
+		if filepath.IsAbs(scope[1]) {
+			return scope[1], nil
+		}
+		return filepath.Join(r.Dir, scope[1]), nil
+	case len(scope) == 1 && scope[0] == "--global":
+		return r.globalConfigFile()
+	}
+
+	out, err := r.command("rev-parse", "--git-path", "config")
+	if err != nil {
+		return "", err
+	}
+	path := strings.TrimSpace(out)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(r.Dir, path)
+	}
+	return path, nil
+}
+
+// Like git, ~/.gitconfig unless only the XDG one exists
+func (r *GitRepo) globalConfigFile() (string, error) {
+	if r.configDir != "" {
+		return r.configDir, nil
+	}
+	if path := os.Getenv("GIT_CONFIG_GLOBAL"); path != "" {
+		return path, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(home, ".gitconfig")
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+
+	xdg := os.Getenv("XDG_CONFIG_HOME")
+	if xdg == "" {
+		xdg = filepath.Join(home, ".config")
+	}
+	if _, err := os.Stat(filepath.Join(xdg, "git", "config")); err == nil {
+		return filepath.Join(xdg, "git", "config"), nil
+	}
+	return path, nil
+}
+
 func (r *GitRepo) GetConfig(option string) (string, error) {
 	out, err := r.command("config", "--get", option)
 	if err != nil {
@@ -305,8 +419,15 @@ func (r *GitRepo) GetVar(str string) (string, error) {
 }
 
 func (r *GitRepo) GetSectionOptions(name string) (map[string]string, error) {
+	return r.GetSectionOptionsIn(nil, name)
+}
+
+// GetSectionOptionsIn reads the section only from the given scope, all of
+// them are read when it is nil
+func (r *GitRepo) GetSectionOptionsIn(scope ConfigScope, name string) (map[string]string, error) {
 	ops := map[string]string{}
-	out, err := r.command("config", "--get-regexp", fmt.Sprintf("^%s.*", name))
+	args := append([]string{"config"}, scope...)
+	out, err := r.command(append(args, "--get-regexp", fmt.Sprintf("^%s.*", name))...)
 	if err != nil {
 		return ops, fmt.Errorf("git config failed, seciton does not exists? %w", err)
 	}
@@ -327,6 +448,51 @@ func (r *GitRepo) GetSectionOptions(name string) (map[string]string, error) {
 	return ops, nil
 }
 
+// GetSectionScopes returns the scope (local, global, system...) each option
+// of the section is read from, the last one wins like in GetSectionOptions
+func (r *GitRepo) GetSectionScopes(name string) (map[string]string, error) {
+	scopes := map[string]string{}
+	out, err := r.command("config", "--show-scope", "--get-regexp", fmt.Sprintf("^%s.*", name))
+	if err != nil {
+		return scopes, fmt.Errorf("git config failed, seciton does not exists? %w", err)
+	}
+
+	for _, option := range strings.Split(strings.TrimSpace(out), "\n") {
+		scope, keyValue, found := strings.Cut(strings.TrimSpace(option), "\t")
+		if !found {
+			return scopes, fmt.Errorf("git config option without scope (%s)", option)
+		}
+		key, _, _ := strings.Cut(keyValue, " ")
+		scopes[strings.Replace(key, fmt.Sprintf("%s.", name), "", 1)] = scope
+	}
+
+	return scopes, nil
+}
+
+// ConfigFiles returns the files setting the key, none when it is not set
+func (r *GitRepo) ConfigFiles(key string) []string {
+	out, err := r.command("config", "--show-origin", "--get-all", key)
+	if err != nil {
+		return nil
+	}
+
+	files := []string{}
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		origin, _, _ := strings.Cut(line, "\t")
+		path, found := strings.CutPrefix(origin, "file:")
+		if !found {
+			continue
+		}
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(r.Dir, path)
+		}
+		if !slices.Contains(files, path) {
+			files = append(files, path)
+		}
+	}
+	return files
+}
+
 func (r *GitRepo) Push() error {
 	_, err := r.command("push", "--all")
 	return err
@@ -357,8 +523,7 @@ func (r *GitRepo) AmendDate(commit *Commit) error {
 		return fmt.Errorf("amendDate: commit %s is not head (%s)", commit.Hash, head.Hash)
 	}
 
-	date := fmt.Sprintf("--date=\"%s\"", commit.Date.Format("Mon, 02 Jan 2006 15:04:05 -0700"))
-	_, err = r.command("commit", "--amend", "--no-edit", date)
+	err = r.AmendDates(GitLog{commit})
 	if err != nil {
 		return fmt.Errorf("amendDate: coulnd't amend the commit %w", err)
 	}
@@ -366,64 +531,118 @@ func (r *GitRepo) AmendDate(commit *Commit) error {
 	return nil
 }
 
+// AmendDates sets the author and committer dates of the commits, oldest
+// first, and rewrites their descendants up to HEAD keeping merges. The dates
+// the changed ones were made at are noted in NotesRef.
 func (r *GitRepo) AmendDates(log GitLog) error {
-	hash, err := r.RootCommitHash()
-	if err != nil {
-		return fmt.Errorf("failed to obtain the root commit hash: %w", err)
-	}
-
-	cmd := []string{"rebase", "--interactive"}
-	if hash == log[0].Hash {
-		cmd = append(cmd, "--root")
-	} else {
-		cmd = append(cmd, fmt.Sprintf("HEAD~%d", len(log)))
+	if len(log) == 0 {
+		return nil
 	}
 
-	var builder strings.Builder
+	hashes := []string{}
 	for _, commit := range log {
-		newDate := commit.Date.Format("Mon, 02 Jan 2006 15:04:05 -0700")
-		builder.WriteString(fmt.Sprintf(
-			"pick %s\nexec GIT_COMMITTER_DATE=\"%s\" git commit --amend --no-edit --date=\"%s\"\n",
-			commit.Hash[:7],
-			newDate,
-			newDate,
-		))
+		hashes = append(hashes, commit.Hash)
 	}
-
-	file, err := os.CreateTemp(os.TempDir(), "git-decent-amend")
+	current, err := r.originalDates(nil, hashes...)
 	if err != nil {
-		return fmt.Errorf("failed to create tmp file for rebase todo: %w", err)
+		return fmt.Errorf("failed to get the dates of the commits: %w", err)
 	}
 
-	defer os.Remove(file.Name())
+	//Commits keeping their dates are left as they are
+	dates := map[string]CommitDates{}
+	for _, commit := range log {
+		now := current[commit.Hash]
+		amended := CommitDates{Author: commit.Date, Committer: commit.CommitterDate}
+		if amended.Committer.IsZero() {
+			amended.Committer = now.Committer.Add(commit.Date.Sub(now.Author))
+			//It moves to the timezone the author is moved to
+			if commit.Date.Format("-0700") != now.Author.Format("-0700") {
+				amended.Committer = amended.Committer.In(commit.Date.Location())
+			}
+		}
+		if !amended.equal(now) {
+			dates[commit.Hash] = amended
+		}
+	}
+	if len(dates) == 0 {
+		return nil
+	}
 
-	_, err = file.WriteString(builder.String())
+	//The parents out of the log, like the ones of main merged, are kept
+	boundary := []string{}
+	for _, commit := range log {
+		for _, parent := range commit.Parents {
+			if !slices.Contains(hashes, parent) && !slices.Contains(boundary, parent) {
+				boundary = append(boundary, parent)
+			}
+		}
+	}
 
+	noted, err := r.notedDates()
 	if err != nil {
-		file.Close()
-		return fmt.Errorf("failed to write the todo file: %w", err)
+		return fmt.Errorf("failed to get the original dates: %w", err)
 	}
 
-	err = file.Close()
+	rw, err := r.rewriteDates(dates, boundary)
 	if err != nil {
-		return fmt.Errorf("failed to close the root commit hash: %w", err)
+		return fmt.Errorf("failed to rewrite the commits: %w", err)
+	}
+	if err := r.moveHead(rw); err != nil {
+		return err
 	}
 
-	editorCommand := fmt.Sprintf("GIT_SEQUENCE_EDITOR=cp %s", file.Name())
-	_, err = r.commandWithEnv([]string{editorCommand}, cmd...)
-	return err
+	//Changed commits note the dates they were made at, unless they got them
+	//back, and the ones amended before keep their notes when rewritten
+	notes := map[string]CommitDates{}
+	for old, rewritten := range rw.rewritten {
+		original, wasNoted := noted[old]
+		if !wasNoted {
+			original = current[old]
+		}
+		amended, changed := dates[old]
+		switch {
+		case changed && !amended.equal(original):
+			notes[rewritten] = original
+		case !changed && wasNoted:
+			notes[rewritten] = original
+		}
+	}
+	if err := r.saveOriginalDates(notes); err != nil {
+		return fmt.Errorf("the dates were amended but the original ones couldn't be noted %w", err)
+	}
+	return nil
 }
 
 func (r *GitRepo) LogWithRevision(revisionRange string) (GitLog, error) {
 	return r.log(revisionRange)
 }
 
+// UnpushedLog returns the commits not in the upstream, leaving out the ones in
+// any remote branch like the ones of main merged into a feature branch
+func (r *GitRepo) UnpushedLog(upstream string) (GitLog, error) {
+	return r.log(upstream+"...", "--not", "--remotes")
+}
+
 func (r *GitRepo) Log() (GitLog, error) {
 	return r.log()
 }
 
+// LogBy returns the commits of the given author (name or email) made since
+// the given date, like git log --author and --since. Empty values match all.
+func (r *GitRepo) LogBy(author string, since string) (GitLog, error) {
+	args := []string{}
+	if author != "" {
+		args = append(args, "--author="+author)
+	}
+	if since != "" {
+		args = append(args, "--since="+since)
+	}
+	return r.log(args...)
+}
+
 func (r *GitRepo) log(args ...string) (GitLog, error) {
-	params := []string{"log", "--pretty=format:%H%x1f%an%x1f%ai%x1f%s%x1f", "--name-only", "--reverse"}
+	//Parents go before their children, merges included
+	params := []string{"log", "--pretty=format:%x1e%H%x1f%P%x1f%an%x1f%ai%x1f%s%x1f", "--name-only", "--reverse", "--topo-order"}
 	params = append(params, args...)
 	output, err := r.command(params...)
 	if err != nil {
@@ -437,27 +656,32 @@ func parseLog(output string) (GitLog, error) {
 	commits := GitLog{}
 	var lastCommit *Commit
 
-	rawCommits := strings.Split(output, "\n\n")
+	//Commits without files, like merges, aren't followed by an empty line
+	rawCommits := strings.Split(output, "\x1e")
 	for _, rawCommit := range rawCommits {
 		parts := strings.Split(rawCommit, "\x1f")
-		if len(parts) < 4 {
+		if len(parts) < 6 {
 			continue
 		}
 		commit := Commit{
 			Hash:    parts[0],
-			Author:  parts[1],
-			Message: parts[3],
+			Parents: strings.Fields(parts[1]),
+			Author:  parts[2],
+			Message: parts[4],
 			Prev:    lastCommit,
 		}
 
-		dateStr := parts[2]
-		files := parts[4]
+		dateStr := parts[3]
+		files := parts[5]
 
 		date, err := time.Parse("2006-01-02 15:04:05 -0700", dateStr)
 		if err != nil {
 			fmt.Println("[WARN]: couldn't parse date from commit log")
 		}
 
+		//time.Parse picks the local timezone when its offset matches the one
+		//the commit was made with, so wall clocks follow its DST changes.
+		//Otherwise the offset is kept as it is.
 		commit.Date = date
 
 		pFiles := strings.Split(files, "\n")
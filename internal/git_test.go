@@ -152,8 +152,10 @@ func TestFixtures(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Len(t, log, 2)
 
-		assert.Equal(t, historyDates[0], log[0].Date)
-		assert.Equal(t, historyDates[1], log[1].Date)
+		for k, date := range historyDates {
+			assert.True(t, date.Equal(log[k].Date))
+			assert.Equal(t, date.Format(time.RFC3339), log[k].Date.Format(time.RFC3339), "the offset is kept")
+		}
 	})
 }
 
@@ -202,6 +204,35 @@ func TestConfig(t *testing.T) {
 	assert.Equal(t, "10:00/11:00", o)
 }
 
+func TestConfigScopes(t *testing.T) {
+	r := NewRepositoryBuilder(t).As(Working).MustBuild()
+
+	local, err := r.ConfigFile(LocalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(r.Dir, ".git/config"), local)
+
+	global, err := r.ConfigFile(GlobalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(r.Dir, ".globalconfig"), global)
+
+	file, err := r.ConfigFile(FileConfig("team.ini"))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(r.Dir, "team.ini"), file)
+
+	for _, scope := range []ConfigScope{LocalConfig, GlobalConfig, FileConfig("team.ini")} {
+		require.NoError(t, r.SetConfigIn(scope, "decent.Saturday", "10:00/12:00"))
+		path, err := r.ConfigFile(scope)
+		require.NoError(t, err)
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Contains(t, string(content), "Saturday = 10:00/12:00", scope)
+	}
+
+	require.NoError(t, r.UnsetConfigIn(FileConfig("team.ini"), "decent.Saturday"))
+	assert.Equal(t, []string{global, local}, r.ConfigFiles("decent.Saturday"))
+	assert.Empty(t, r.ConfigFiles("decent.Sunday"))
+}
+
 func TestGetVar(t *testing.T) {
 	r := NewRepositoryBuilder(t).As(Working).MustBuild()
 
@@ -291,6 +322,40 @@ func TestLogBeforeAndAfter(t *testing.T) {
 	assert.NotNil(t, cs[0].Next, "First commit should be linked to the second")
 }
 
+func TestLogBy(t *testing.T) {
+	dir := createTempDir(t, "git-decent-test-log-by")
+	os.WriteFile(filepath.Join(dir, "fixture"), []byte("test fixture"), 0666)
+	os.WriteFile(filepath.Join(dir, "fixture2"), []byte("test fixture2"), 0666)
+	c := Commit{
+		Message: "Some commit message",
+		Date:    time.Date(2000, 12, 20, 1, 2, 3, 4, time.UTC),
+		Author:  "Git test <withcommits@git-decent.git>",
+		Files:   []string{"fixture"},
+	}
+	c2 := c
+	c2.Date = time.Date(2001, 12, 21, 1, 2, 3, 4, time.UTC)
+	c2.Author = "Other test <other@git-decent.git>"
+	c2.Files = []string{"fixture2"}
+	repo := NewRepositoryBuilder(t).At(dir).AddCommit(&c).AddCommit(&c2).MustBuild()
+
+	cs, err := repo.LogBy("", "")
+	require.NoError(t, err)
+	assert.Len(t, cs, 2)
+
+	cs, err = repo.LogBy("other@git-decent.git", "")
+	require.NoError(t, err)
+	require.Len(t, cs, 1)
+	assert.Equal(t, "Other test", cs[0].Author)
+
+	//Like git log, since is about the commit date and not the author one
+	cs, err = repo.LogBy("", "1 hour ago")
+	require.NoError(t, err)
+	assert.Len(t, cs, 2)
+	cs, err = repo.LogBy("", time.Now().Add(time.Hour).Format(time.RFC3339))
+	require.NoError(t, err)
+	assert.Empty(t, cs)
+}
+
 func TestRootCommitHash(t *testing.T) {
 	repo := NewRepositoryBuilder(t).WithRandomCommits(2).MustBuild()
 	log, err := repo.Log()
@@ -375,3 +440,186 @@ func TestAmendSingleCommit(t *testing.T) {
 	fmt.Println(changedLog[0].Date, origDate)
 	assert.Equal(t, changedLog[0].Date, changedDate)
 }
+
+func TestOriginalDates(t *testing.T) {
+	repo := NewRepositoryBuilder(t).WithRandomCommits(3).MustBuild()
+	log, err := repo.LogWithRevision("-3")
+	require.NoError(t, err)
+	require.Len(t, log, 3)
+
+	original, err := repo.OriginalDates(log[2].Hash)
+	require.NoError(t, err)
+	assert.Nil(t, original, "commits never amended have no original dates")
+
+	made := log[2].Date
+	log[2].Date = time.Date(2022, 02, 1, 10, 0, 0, 0, made.Location())
+	require.NoError(t, repo.AmendDate(log[2]))
+
+	//Amending again keeps the dates the commit was made at
+	amended, err := repo.LogWithRevision("-3")
+	require.NoError(t, err)
+	amended[2].Date = time.Date(2022, 02, 1, 11, 0, 0, 0, made.Location())
+	require.NoError(t, repo.AmendDate(amended[2]))
+
+	amended, err = repo.LogWithRevision("-3")
+	require.NoError(t, err)
+	original, err = repo.OriginalDates(amended[2].Hash)
+	require.NoError(t, err)
+	require.NotNil(t, original)
+	assert.True(t, made.Equal(original.Author))
+
+	//Notes are carried to the rewritten commits
+	for k := range amended {
+		amended[k].Date = time.Date(2022, 02, k+2, 0, 0, 0, 0, made.Location())
+	}
+	require.NoError(t, repo.AmendDates(amended))
+
+	rebased, err := repo.LogWithRevision("-3")
+	require.NoError(t, err)
+	for k, commit := range rebased {
+		original, err := repo.OriginalDates(commit.Hash)
+		require.NoError(t, err)
+		require.NotNil(t, original)
+		assert.True(t, log[k].Date.Equal(original.Author) || k == 2)
+	}
+	original, err = repo.OriginalDates(rebased[2].Hash)
+	require.NoError(t, err)
+	assert.True(t, made.Equal(original.Author))
+}
+
+func TestOriginalDatesOnlyOfChangedCommits(t *testing.T) {
+	repo := NewRepositoryBuilder(t).WithRandomCommits(4).MustBuild()
+	log, err := repo.LogWithRevision("-3")
+	require.NoError(t, err)
+	made := log[0].Date
+
+	//The second one keeps its date and the third is only rewritten
+	log[0].Date = time.Date(2022, 02, 1, 10, 0, 0, 0, made.Location())
+	require.NoError(t, repo.AmendDates(log[:2]))
+
+	amended, err := repo.LogWithRevision("-3")
+	require.NoError(t, err)
+	for k, commit := range amended {
+		original, err := repo.OriginalDates(commit.Hash)
+		require.NoError(t, err)
+		if k == 0 {
+			require.NotNil(t, original)
+			assert.True(t, made.Equal(original.Author))
+			continue
+		}
+		assert.Nil(t, original, "commit %d keeps its dates", k)
+	}
+
+	//Nothing to note once the dates are back
+	restored, err := repo.WithOriginalDates(amended[:1])
+	require.NoError(t, err)
+	require.NoError(t, repo.AmendDates(restored))
+	back, err := repo.LogWithRevision("-3")
+	require.NoError(t, err)
+	assert.True(t, made.Equal(back[0].Date))
+	original, err := repo.OriginalDates(back[0].Hash)
+	require.NoError(t, err)
+	assert.Nil(t, original)
+
+	//Same dates, nothing is rewritten
+	require.NoError(t, repo.AmendDates(back))
+	again, err := repo.LogWithRevision("-3")
+	require.NoError(t, err)
+	assert.Equal(t, back[2].Hash, again[2].Hash)
+}
+
+func TestParseOriginalDates(t *testing.T) {
+	dates, err := parseOriginalDates("author 2024-01-28T18:30:00+02:00\ncommitter 2024-01-28T18:31:00+02:00\n")
+	require.NoError(t, err)
+	assert.Equal(t, "2024-01-28T18:30:00+02:00", dates.Author.Format(time.RFC3339))
+	assert.Equal(t, "2024-01-28T18:31:00+02:00", dates.Committer.Format(time.RFC3339))
+	assert.Equal(t, "author 2024-01-28T18:30:00+02:00\ncommitter 2024-01-28T18:31:00+02:00\n", dates.note())
+
+	_, err = parseOriginalDates("author yesterday")
+	assert.Error(t, err)
+}
+
+func TestWithOriginalDates(t *testing.T) {
+	repo := NewRepositoryBuilder(t).WithRandomCommits(3).MustBuild()
+	log, err := repo.LogWithRevision("-2")
+	require.NoError(t, err)
+	made := []time.Time{log[0].Date, log[1].Date}
+	committers, err := repo.command("log", "-2", "--format=%cI")
+	require.NoError(t, err)
+
+	for k := range log {
+		log[k].Date = time.Date(2022, 02, k+1, 9, 0, 0, 0, made[k].Location())
+		log[k].CommitterDate = log[k].Date.Add(time.Hour)
+	}
+	require.NoError(t, repo.AmendDates(log))
+
+	amended, err := repo.LogWithRevision("-3")
+	require.NoError(t, err)
+	restored, err := repo.WithOriginalDates(amended)
+	require.NoError(t, err)
+	assert.Equal(t, amended[0].Date, restored[0].Date, "commits never amended keep their date")
+	assert.True(t, made[0].Equal(restored[1].Date))
+	assert.True(t, made[1].Equal(restored[2].Date))
+	assert.Equal(t, time.Date(2022, 02, 2, 9, 0, 0, 0, made[1].Location()), amended[2].Date, "the log isn't changed")
+
+	require.NoError(t, repo.AmendDates(restored[1:]))
+	back, err := repo.LogWithRevision("-2")
+	require.NoError(t, err)
+	assert.True(t, made[0].Equal(back[0].Date))
+	assert.True(t, made[1].Equal(back[1].Date))
+	restoredCommitters, err := repo.command("log", "-2", "--format=%cI")
+	require.NoError(t, err)
+	assert.Equal(t, committers, restoredCommitters, "committer dates are restored too")
+}
+
+func TestAmendDatesWithMerges(t *testing.T) {
+	bare := NewRepositoryBuilder(t).As(Bare).MustBuild()
+	repo := NewRepositoryBuilder(t).Clone(bare.Dir).WithRandomCommits(2).MustBuild()
+	require.NoError(t, repo.Push())
+	_, err := repo.command("checkout", "-b", "feature")
+	require.NoError(t, err)
+	_, err = repo.command("push", "--set-upstream", "origin", "feature")
+	require.NoError(t, err)
+
+	commit := func(branch string) {
+		c, err := NewFixtureCommit(repo)
+		require.NoError(t, err)
+		c.Message += " in " + branch
+		require.NoError(t, repo.Commit(c))
+	}
+	commit("feature")
+	_, err = repo.command("checkout", "main")
+	require.NoError(t, err)
+	commit("main")
+	_, err = repo.command("push", "origin", "main")
+	require.NoError(t, err)
+	main, err := repo.LogWithRevision("-1")
+	require.NoError(t, err)
+	_, err = repo.command("checkout", "feature")
+	require.NoError(t, err)
+	_, err = repo.command("merge", "--no-edit", "main")
+	require.NoError(t, err)
+	commit("feature")
+
+	//The commits of main are already pushed
+	log, err := repo.UnpushedLog(repo.BranchUpstream("feature"))
+	require.NoError(t, err)
+	require.Len(t, log, 3)
+	require.Len(t, log[1].Parents, 2)
+	assert.Equal(t, []string{log[0].Hash, main[0].Hash}, log[1].Parents)
+
+	for k := range log {
+		log[k].Date = time.Date(2022, 02, k+1, 9, 0, 0, 0, log[k].Date.Location())
+	}
+	require.NoError(t, repo.AmendDates(log))
+
+	amended, err := repo.UnpushedLog(repo.BranchUpstream("feature"))
+	require.NoError(t, err)
+	require.Len(t, amended, 3)
+	for k, commit := range amended {
+		assert.Equal(t, time.Date(2022, 02, k+1, 9, 0, 0, 0, commit.Date.Location()), commit.Date)
+		assert.Equal(t, log[k].Message, commit.Message)
+	}
+	assert.Equal(t, []string{amended[0].Hash, main[0].Hash}, amended[1].Parents, "merges keep their parents")
+	assert.Equal(t, []string{amended[1].Hash}, amended[2].Parents)
+}
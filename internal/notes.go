@@ -0,0 +1,235 @@
+/* SPDX-License-Identifier: MIT */
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/afiestas/git-decent/config"
+)
+
+// NotesRef keeps the dates commits were made at before amending them. Git
+// doesn't push notes by default and the pre-push hook refuses this ref, it
+// takes git push --no-verify origin refs/notes/decent to push them.
+const NotesRef = "refs/notes/decent"
+
+// The author and committer dates of a commit
+type CommitDates struct {
+	Author    time.Time
+	Committer time.Time
+}
+
+// OriginalDates returns the dates the commit was made at, nil when it was
+// never amended
+func (r *GitRepo) OriginalDates(hash string) (*CommitDates, error) {
+	//The message is matched, it can't be translated
+	output, err := r.commandWithEnv([]string{"LC_ALL=C"}, "notes", "--ref="+NotesRef, "show", hash)
+	if err != nil {
+		var cmdErr *CommandError
+		if errors.As(err, &cmdErr) && strings.Contains(cmdErr.Stderr, "no note found") {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("couldn't read the original dates of %s %w", hash, err)
+	}
+
+	return parseOriginalDates(output)
+}
+
+func parseOriginalDates(note string) (*CommitDates, error) {
+	dates := &CommitDates{}
+	for _, line := range strings.Split(strings.TrimSpace(note), "\n") {
+		key, value, _ := strings.Cut(strings.TrimSpace(line), " ")
+		date, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date in the decent note %q %w", line, err)
+		}
+		switch key {
+		case "author":
+			dates.Author = date
+		case "committer":
+			dates.Committer = date
+		}
+	}
+	if dates.Author.IsZero() || dates.Committer.IsZero() {
+		return nil, fmt.Errorf("the decent note lacks the author or committer date: %q", note)
+	}
+	return dates, nil
+}
+
+// Same instants with the same offsets, as git writes them
+func (d CommitDates) equal(other CommitDates) bool {
+	return config.SameDate(d.Author, other.Author) && config.SameDate(d.Committer, other.Committer)
+}
+
+func (d CommitDates) note() string {
+	return fmt.Sprintf("author %s\ncommitter %s\n", d.Author.Format(time.RFC3339), d.Committer.Format(time.RFC3339))
+}
+
+// notedDates returns the original dates of all the amended commits by their
+// hash, read at once
+func (r *GitRepo) notedDates() (map[string]CommitDates, error) {
+	output, err := r.command("notes", "--ref="+NotesRef, "list")
+	if err != nil {
+		return nil, fmt.Errorf("couldn't list the original dates %w", err)
+	}
+
+	//Each line is the note blob followed by the commit, commits with the
+	//same dates share the blob
+	blobs := map[string]string{}
+	unique := []string{}
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		blob, commit, found := strings.Cut(line, " ")
+		if !found {
+			continue
+		}
+		if _, exists := blobs[commit]; !exists && !slices.Contains(unique, blob) {
+			unique = append(unique, blob)
+		}
+		blobs[commit] = blob
+	}
+	notes, err := r.readObjects(unique, "blob")
+	if err != nil {
+		return nil, err
+	}
+
+	noted := map[string]CommitDates{}
+	for commit, blob := range blobs {
+		dates, err := parseOriginalDates(notes[blob])
+		if err != nil {
+			return nil, err
+		}
+		noted[commit] = *dates
+	}
+	return noted, nil
+}
+
+// originalDates returns the dates the commits were made at by their hash, the
+// noted ones when they were amended before and their current ones otherwise
+func (r *GitRepo) originalDates(noted map[string]CommitDates, hashes ...string) (map[string]CommitDates, error) {
+	args := []string{"show", "--no-patch", "--format=%H%x1f%aI%x1f%cI"}
+	output, err := r.command(append(args, hashes...)...)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get the dates of the commits %w", err)
+	}
+
+	originals := map[string]CommitDates{}
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		parts := strings.Split(line, "\x1f")
+		if len(parts) != 3 {
+			continue
+		}
+		if dates, exists := noted[parts[0]]; exists {
+			originals[parts[0]] = dates
+			continue
+		}
+
+		dates := CommitDates{}
+		if dates.Author, err = time.Parse(time.RFC3339, parts[1]); err != nil {
+			return nil, fmt.Errorf("couldn't parse the author date of %s %w", parts[0], err)
+		}
+		if dates.Committer, err = time.Parse(time.RFC3339, parts[2]); err != nil {
+			return nil, fmt.Errorf("couldn't parse the committer date of %s %w", parts[0], err)
+		}
+		originals[parts[0]] = dates
+	}
+	if len(originals) != len(hashes) {
+		return nil, fmt.Errorf("expected the dates of %d commits, got %d", len(hashes), len(originals))
+	}
+	return originals, nil
+}
+
+// saveOriginalDates notes the dates of the commits in a single notes commit
+func (r *GitRepo) saveOriginalDates(originals map[string]CommitDates) error {
+	if len(originals) == 0 {
+		return nil
+	}
+
+	ident, err := r.GetVar("GIT_COMMITTER_IDENT")
+	if err != nil {
+		return fmt.Errorf("couldn't get the committer identity %w", err)
+	}
+
+	message := "git decent: original dates\n"
+	var script strings.Builder
+	fmt.Fprintf(&script, "commit %s\ncommitter %s\ndata %d\n%s", NotesRef, strings.TrimSpace(ident), len(message), message)
+	if _, err := r.command("rev-parse", "--verify", "--quiet", NotesRef); err == nil {
+		fmt.Fprintf(&script, "from %s^0\n", NotesRef)
+	}
+	for hash, dates := range originals {
+		note := dates.note()
+		fmt.Fprintf(&script, "N inline %s\ndata %d\n%s", hash, len(note), note)
+	}
+
+	_, err = r.commandWithInput(script.String(), "fast-import", "--quiet")
+	if err != nil {
+		return fmt.Errorf("couldn't save the original dates %w", err)
+	}
+	return nil
+}
+
+// BatchOf returns the dates of the commits to amend with the real ones noted
+// for them, after the latest parent of the first one so amended commits don't
+// go before what they are built on
+func (r *GitRepo) BatchOf(log GitLog) (Batch, error) {
+	batch := Batch{}
+	if len(log) == 0 {
+		return batch, nil
+	}
+
+	originals, err := r.WithOriginalDates(log)
+	if err != nil {
+		return batch, err
+	}
+	for k, commit := range log {
+		batch.Dates = append(batch.Dates, commit.Date)
+		batch.RealDates = append(batch.RealDates, originals[k].Date)
+	}
+
+	var last *Commit
+	for _, parent := range log[0].Parents {
+		//Only the parent, not its history
+		commits, err := r.LogWithRevision(parent + "^!")
+		if err != nil {
+			return batch, fmt.Errorf("couldn't get the parent %s %w", parent, err)
+		}
+		for _, commit := range commits {
+			if last == nil || commit.Date.After(last.Date) {
+				last = commit
+			}
+		}
+	}
+	if last == nil {
+		return batch, nil
+	}
+
+	lastOriginal, err := r.WithOriginalDates(GitLog{last})
+	if err != nil {
+		return batch, err
+	}
+	batch.LastDate = &last.Date
+	batch.LastRealDate = &lastOriginal[0].Date
+	return batch, nil
+}
+
+// WithOriginalDates returns copies of the commits dated when they were made,
+// by their author and committer, the ones never amended keep their date
+func (r *GitRepo) WithOriginalDates(log GitLog) (GitLog, error) {
+	noted, err := r.notedDates()
+	if err != nil {
+		return nil, err
+	}
+
+	restored := GitLog{}
+	for _, commit := range log {
+		c := *commit
+		if original, exists := noted[commit.Hash]; exists {
+			c.Date = original.Author
+			c.CommitterDate = original.Committer
+		}
+		restored = append(restored, &c)
+	}
+	return restored, nil
+}
@@ -0,0 +1,261 @@
+/* SPDX-License-Identifier: MIT */
+package internal
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"crypto/sha256"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Commits are rewritten with plumbing: their objects are read, only the dates
+// and the parents of rewritten ones change and HEAD is moved to the result.
+// Merges keep all their parents, so the history keeps its shape, and signed
+// commits are signed again.
+// Trees and messages stay byte-identical, no hook runs and neither the
+// working tree nor the index are touched.
+
+// A commit object as stored by git, headers without their newline
+type commitObject struct {
+	headers []string
+	message string
+}
+
+// Commits written but not in the history yet
+type rewriting struct {
+	head string
+	// New hashes by the old ones
+	rewritten map[string]string
+}
+
+// rewriteDates writes the commits of HEAD not reachable from the boundary ones
+// with the new dates, their descendants get the new parents
+func (r *GitRepo) rewriteDates(dates map[string]CommitDates, boundary []string) (*rewriting, error) {
+	head, err := r.command("rev-parse", "--verify", "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get HEAD %w", err)
+	}
+	head = strings.TrimSpace(head)
+
+	args := []string{"rev-list", "--reverse", "--topo-order", head, "--not"}
+	output, err := r.command(append(args, boundary...)...)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't list the commits to rewrite %w", err)
+	}
+	commits := strings.Fields(output)
+
+	inRange := map[string]bool{}
+	for _, hash := range commits {
+		inRange[hash] = true
+	}
+	for hash := range dates {
+		if !inRange[hash] {
+			return nil, fmt.Errorf("commit %s is not in the history of HEAD", hash)
+		}
+	}
+
+	raw, err := r.readObjects(commits, "commit")
+	if err != nil {
+		return nil, err
+	}
+	newHash, signatureHeader, err := r.objectHasher()
+	if err != nil {
+		return nil, err
+	}
+
+	rw := &rewriting{head: head, rewritten: map[string]string{}}
+	contents := map[string]string{}
+	var signer *signer
+	for _, hash := range commits {
+		object := parseCommitObject(raw[hash])
+		//Commits not amended nor descending from one are kept
+		if _, amend := dates[hash]; !amend && !object.hasRewrittenParent(rw.rewritten) {
+			continue
+		}
+		content := object.withDates(dates, hash, rw.rewritten)
+		if object.signature() != "" {
+			if signer == nil {
+				if signer, err = r.newSigner(); err != nil {
+					return nil, fmt.Errorf("commit %s is signed and it can't be signed again: %w", hash, err)
+				}
+			}
+			signature, err := signer.sign(content)
+			if err != nil {
+				return nil, fmt.Errorf("couldn't sign commit %s again: %w", hash, err)
+			}
+			content = withSignature(content, signatureHeader, signature)
+		}
+		h := newHash()
+		fmt.Fprintf(h, "commit %d\x00%s", len(content), content)
+		rw.rewritten[hash] = fmt.Sprintf("%x", h.Sum(nil))
+		contents[rw.rewritten[hash]] = content
+	}
+
+	if err := r.writeObjects(contents, "commit"); err != nil {
+		return nil, err
+	}
+	return rw, nil
+}
+
+// moveHead points HEAD to the rewritten commits, unless it moved meanwhile
+func (r *GitRepo) moveHead(rw *rewriting) error {
+	_, err := r.command("update-ref", "-m", "git decent: amend dates", "HEAD", rw.rewritten[rw.head], rw.head)
+	if err != nil {
+		return fmt.Errorf("couldn't move HEAD to the amended commits %w", err)
+	}
+	return nil
+}
+
+func parseCommitObject(raw string) commitObject {
+	headers, message, _ := strings.Cut(raw, "\n\n")
+	return commitObject{headers: strings.Split(headers, "\n"), message: message}
+}
+
+func (c commitObject) hasRewrittenParent(rewritten map[string]string) bool {
+	for _, header := range c.headers {
+		if key, value, _ := strings.Cut(header, " "); key == "parent" && rewritten[value] != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// The header of the signature, empty when the commit isn't signed
+func (c commitObject) signature() string {
+	for _, header := range c.headers {
+		if key, _, _ := strings.Cut(header, " "); key == "gpgsig" || key == "gpgsig-sha256" {
+			return key
+		}
+	}
+	return ""
+}
+
+// The content of the commit with its dates, when given, and its parents
+// rewritten. The signature is dropped since it doesn't match anymore.
+func (c commitObject) withDates(dates map[string]CommitDates, hash string, rewritten map[string]string) string {
+	date, amend := dates[hash]
+	headers := []string{}
+	for k := 0; k < len(c.headers); k++ {
+		header := c.headers[k]
+		key, value, _ := strings.Cut(header, " ")
+		switch {
+		case key == "parent" && rewritten[value] != "":
+			header = "parent " + rewritten[value]
+		case key == "author" && amend:
+			header = key + " " + withDate(value, date.Author)
+		case key == "committer" && amend:
+			header = key + " " + withDate(value, date.Committer)
+		case key == "gpgsig" || key == "gpgsig-sha256":
+			//Continuation lines start with a space
+			for k+1 < len(c.headers) && strings.HasPrefix(c.headers[k+1], " ") {
+				k++
+			}
+			continue
+		}
+		headers = append(headers, header)
+	}
+	return strings.Join(headers, "\n") + "\n\n" + c.message
+}
+
+// The identity of an author or committer line with the date replaced
+func withDate(identity string, date time.Time) string {
+	end := strings.LastIndex(identity, ">")
+	if end == -1 {
+		return identity
+	}
+	return fmt.Sprintf("%s %d %s", identity[:end+1], date.Unix(), date.Format("-0700"))
+}
+
+// The hash of the objects and the header git signs commits with, both
+// depend on the object format of the repository
+func (r *GitRepo) objectHasher() (func() hash.Hash, string, error) {
+	format, err := r.command("rev-parse", "--show-object-format")
+	if err != nil {
+		return nil, "", fmt.Errorf("couldn't get the object format %w", err)
+	}
+
+	switch strings.TrimSpace(format) {
+	case "sha1":
+		return sha1.New, "gpgsig", nil
+	case "sha256":
+		return sha256.New, "gpgsig-sha256", nil
+	}
+	return nil, "", fmt.Errorf("unknown object format %s", format)
+}
+
+// The content of the objects by their hash, read with a single cat-file
+func (r *GitRepo) readObjects(hashes []string, kind string) (map[string]string, error) {
+	objects := map[string]string{}
+	if len(hashes) == 0 {
+		return objects, nil
+	}
+
+	output, err := r.commandWithInput(strings.Join(hashes, "\n")+"\n", "cat-file", "--batch")
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read the objects %w", err)
+	}
+
+	reader := bufio.NewReader(strings.NewReader(output))
+	for _, hash := range hashes {
+		header, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, fmt.Errorf("couldn't read object %s %w", hash, err)
+		}
+		fields := strings.Fields(header)
+		if len(fields) != 3 || fields[1] != kind {
+			return nil, fmt.Errorf("expected a %s for %s, got %s", kind, hash, strings.TrimSpace(header))
+		}
+		size, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("invalid size of object %s %w", hash, err)
+		}
+
+		//The content is followed by a newline
+		content := make([]byte, size+1)
+		if _, err := io.ReadFull(reader, content); err != nil {
+			return nil, fmt.Errorf("couldn't read object %s %w", hash, err)
+		}
+		objects[hash] = string(content[:size])
+	}
+	return objects, nil
+}
+
+// Writes the objects with a single hash-object, checking they got the hash
+// they are referenced with
+func (r *GitRepo) writeObjects(contents map[string]string, kind string) error {
+	dir, err := os.MkdirTemp(os.TempDir(), "git-decent-rewrite")
+	if err != nil {
+		return fmt.Errorf("couldn't create a directory for the objects %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	hashes := []string{}
+	paths := []string{}
+	for hash, content := range contents {
+		path := filepath.Join(dir, hash)
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			return fmt.Errorf("couldn't write object %s %w", hash, err)
+		}
+		hashes = append(hashes, hash)
+		paths = append(paths, path)
+	}
+
+	output, err := r.commandWithInput(strings.Join(paths, "\n")+"\n", "hash-object", "-w", "-t", kind, "--stdin-paths")
+	if err != nil {
+		return fmt.Errorf("couldn't write the objects %w", err)
+	}
+	written := strings.Fields(output)
+	for k, hash := range hashes {
+		if k >= len(written) || written[k] != hash {
+			return fmt.Errorf("object %s was written with a different hash", hash)
+		}
+	}
+	return nil
+}
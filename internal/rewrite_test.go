@@ -0,0 +1,108 @@
+/* SPDX-License-Identifier: MIT */
+package internal
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRewriteDates(t *testing.T) {
+	repo := NewRepositoryBuilder(t).WithRandomCommits(5).MustBuild()
+	before, err := repo.command("log", "--format=%T%x1f%B%x1e")
+	require.NoError(t, err)
+
+	//Neither a dirty tree nor the hooks get in the way
+	require.NoError(t, os.WriteFile(filepath.Join(repo.Dir, "fixture_1"), []byte("dirty"), 0666))
+	require.NoError(t, os.WriteFile(filepath.Join(repo.Dir, "staged"), []byte("staged"), 0666))
+	_, err = repo.command("add", "staged")
+	require.NoError(t, err)
+	status, err := repo.command("status", "--porcelain")
+	require.NoError(t, err)
+	hook := filepath.Join(repo.Dir, ".git", "hooks", "post-commit")
+	require.NoError(t, os.WriteFile(hook, []byte("#!/bin/sh\ntouch hook-ran\n"), 0755))
+
+	gaps, err := repo.command("log", "-3", "--reverse", "--format=%at %ct")
+	require.NoError(t, err)
+	log, err := repo.LogWithRevision("-3")
+	require.NoError(t, err)
+	for k := range log {
+		log[k].Date = time.Date(2022, 02, k+1, 9, 30, 0, 0, time.FixedZone("", -5*60*60))
+	}
+	require.NoError(t, repo.AmendDates(log))
+
+	after, err := repo.command("log", "--format=%T%x1f%B%x1e")
+	require.NoError(t, err)
+	assert.Equal(t, before, after, "trees and messages are kept")
+
+	dates, err := repo.command("log", "-3", "--reverse", "--format=%ad", "--date=iso-strict")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"2022-02-01T09:30:00-05:00",
+		"2022-02-02T09:30:00-05:00",
+		"2022-02-03T09:30:00-05:00",
+	}, strings.Split(strings.TrimSpace(dates), "\n"))
+
+	//Committers keep their distance to the authors
+	amendedGaps, err := repo.command("log", "-3", "--reverse", "--format=%at %ct")
+	require.NoError(t, err)
+	made, amended := strings.Split(strings.TrimSpace(gaps), "\n"), strings.Split(strings.TrimSpace(amendedGaps), "\n")
+	for k := range made {
+		var author, committer, amendedAuthor, amendedCommitter int64
+		fmt.Sscan(made[k], &author, &committer)
+		fmt.Sscan(amended[k], &amendedAuthor, &amendedCommitter)
+		assert.Equal(t, committer-author, amendedCommitter-amendedAuthor)
+	}
+
+	count, err := repo.command("rev-list", "--count", "HEAD")
+	require.NoError(t, err)
+	assert.Equal(t, "5", strings.TrimSpace(count))
+
+	afterStatus, err := repo.command("status", "--porcelain")
+	require.NoError(t, err)
+	assert.Equal(t, status, afterStatus, "the working tree and the index are untouched")
+	assert.NoFileExists(t, filepath.Join(repo.Dir, "hook-ran"))
+
+	t.Run("Commit not in HEAD", func(t *testing.T) {
+		_, err := repo.rewriteDates(map[string]CommitDates{strings.Repeat("0", 40): {Author: time.Now(), Committer: time.Now()}}, []string{log[0].Hash})
+		assert.Error(t, err)
+	})
+}
+
+func TestObjectHasher(t *testing.T) {
+	repo := NewRepositoryBuilder(t).WithRandomCommits(1).MustBuild()
+	_, header, err := repo.objectHasher()
+	require.NoError(t, err)
+	assert.Equal(t, "gpgsig", header)
+
+	dir := createTempDir(t, "git-decent-test-sha256")
+	if err := exec.Command("git", "init", "--quiet", "--object-format=sha256", dir).Run(); err != nil {
+		t.Skip("git doesn't support sha256 repositories")
+	}
+	sha256Repo, err := NewGitRepo(dir, false)
+	require.NoError(t, err)
+	newHash, header, err := sha256Repo.objectHasher()
+	require.NoError(t, err)
+	assert.Equal(t, "gpgsig-sha256", header)
+	assert.Equal(t, 32, newHash().Size())
+}
+
+func TestWithDate(t *testing.T) {
+	date := time.Date(2022, 02, 1, 9, 30, 0, 0, time.FixedZone("", 5*60*60+30*60))
+	assert.Equal(t, "Git Decent <test@gitdecent.io> 1643688000 +0530", withDate("Git Decent <test@gitdecent.io> 1706462000 +0200", date))
+	assert.Equal(t, "no identity", withDate("no identity", date))
+}
+
+func TestWithDates(t *testing.T) {
+	object := parseCommitObject("tree t\nparent a\nparent b\nauthor A <a@b> 1 +0000\ncommitter C <c@d> 1 +0000\ngpgsig -----BEGIN-----\n signature\n -----END-----\n\nmessage\n\nbody\n")
+	dates := CommitDates{Author: time.Unix(2, 0).In(time.UTC), Committer: time.Unix(3, 0).In(time.UTC)}
+	content := object.withDates(map[string]CommitDates{"c": dates}, "c", map[string]string{"b": "z"})
+	assert.Equal(t, "tree t\nparent a\nparent z\nauthor A <a@b> 2 +0000\ncommitter C <c@d> 3 +0000\n\nmessage\n\nbody\n", content)
+}
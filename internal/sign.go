@@ -0,0 +1,187 @@
+/* SPDX-License-Identifier: MIT */
+package internal
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Signs commits like git commit -S does, with the program and the key of
+// gpg.format and user.signingkey
+type signer struct {
+	format  string
+	program string
+	key     string
+	dir     string
+}
+
+// newSigner fails when there is no way to sign, like a missing key or program
+func (r *GitRepo) newSigner() (*signer, error) {
+	format := r.optionalConfig("gpg.format")
+	if format == "" {
+		format = "openpgp"
+	}
+
+	s := &signer{format: format, dir: r.Dir}
+	switch format {
+	case "openpgp":
+		s.program = r.optionalConfig("gpg.openpgp.program")
+		if s.program == "" {
+			s.program = r.optionalConfig("gpg.program")
+		}
+		if s.program == "" {
+			s.program = "gpg"
+		}
+	case "x509":
+		s.program = r.optionalConfig("gpg.x509.program")
+		if s.program == "" {
+			s.program = "gpgsm"
+		}
+	case "ssh":
+		s.program = r.optionalConfig("gpg.ssh.program")
+		if s.program == "" {
+			s.program = "ssh-keygen"
+		}
+	default:
+		return nil, fmt.Errorf("unknown gpg.format %s, expected openpgp, x509 or ssh", format)
+	}
+
+	if _, err := exec.LookPath(s.program); err != nil {
+		return nil, fmt.Errorf("couldn't find %s to sign with %s %w", s.program, format, err)
+	}
+
+	key, err := r.signingKey(format)
+	if err != nil {
+		return nil, err
+	}
+	s.key = key
+	return s, nil
+}
+
+// The user.signingkey, for gpg the committer identity when it is not set
+func (r *GitRepo) signingKey(format string) (string, error) {
+	key := r.optionalConfig("user.signingkey", "--type=path")
+	if format != "ssh" {
+		if key != "" {
+			return key, nil
+		}
+		ident, err := r.GetVar("GIT_COMMITTER_IDENT")
+		if err != nil {
+			return "", fmt.Errorf("couldn't get the committer identity to sign %w", err)
+		}
+		if end := strings.LastIndex(ident, ">"); end != -1 {
+			ident = ident[:end+1]
+		}
+		return ident, nil
+	}
+
+	if key == "" {
+		command := r.optionalConfig("gpg.ssh.defaultKeyCommand")
+		if command == "" {
+			return "", errors.New("user.signingkey or gpg.ssh.defaultKeyCommand are needed to sign with ssh")
+		}
+		output, err := exec.Command("sh", "-c", command).Output()
+		if err != nil {
+			return "", fmt.Errorf("gpg.ssh.defaultKeyCommand failed %w", err)
+		}
+		key, _, _ = strings.Cut(strings.TrimSpace(string(output)), "\n")
+	}
+	if isLiteralKey(key) {
+		return key, nil
+	}
+	if _, err := os.Stat(key); err != nil {
+		return "", fmt.Errorf("couldn't read the ssh signing key %w", err)
+	}
+	return key, nil
+}
+
+// Like git, "key::" or "ssh-" start a public key instead of the path of one
+func isLiteralKey(key string) bool {
+	return strings.HasPrefix(key, "key::") || strings.HasPrefix(key, "ssh-")
+}
+
+// A value of the configuration, empty when it is not set
+func (r *GitRepo) optionalConfig(key string, args ...string) string {
+	args = append([]string{"config"}, args...)
+	value, err := r.command(append(args, "--get", key)...)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(value)
+}
+
+// The detached signature of the payload, armored
+func (s *signer) sign(payload string) (string, error) {
+	if s.format == "ssh" {
+		return s.sshSign(payload)
+	}
+
+	output, err := s.run(strings.NewReader(payload), "--status-fd=2", "-bsau", s.key)
+	if err != nil {
+		return "", err
+	}
+	return output, nil
+}
+
+// ssh-keygen reads the payload from a file and writes the signature next to it
+func (s *signer) sshSign(payload string) (string, error) {
+	dir, err := os.MkdirTemp(os.TempDir(), "git-decent-sign")
+	if err != nil {
+		return "", fmt.Errorf("couldn't create a directory to sign %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	key := s.key
+	args := []string{"-Y", "sign", "-n", "git", "-f"}
+	if isLiteralKey(key) {
+		//The private key is taken from the ssh agent
+		key = filepath.Join(dir, "key.pub")
+		if err := os.WriteFile(key, []byte(strings.TrimPrefix(s.key, "key::")), 0600); err != nil {
+			return "", fmt.Errorf("couldn't write the signing key %w", err)
+		}
+		args = append(args, key, "-U")
+	} else {
+		args = append(args, key)
+	}
+
+	buffer := filepath.Join(dir, "commit")
+	if err := os.WriteFile(buffer, []byte(payload), 0600); err != nil {
+		return "", fmt.Errorf("couldn't write the commit to sign %w", err)
+	}
+	if _, err := s.run(nil, append(args, buffer)...); err != nil {
+		return "", err
+	}
+
+	signature, err := os.ReadFile(buffer + ".sig")
+	if err != nil {
+		return "", fmt.Errorf("couldn't read the signature %w", err)
+	}
+	return string(signature), nil
+}
+
+func (s *signer) run(stdin *strings.Reader, args ...string) (string, error) {
+	cmd := exec.Command(s.program, args...)
+	cmd.Dir = s.dir
+	if stdin != nil {
+		cmd.Stdin = stdin
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("%s couldn't sign with %s: %s %w", s.program, s.key, strings.TrimSpace(stderr.String()), err)
+	}
+	return stdout.String(), nil
+}
+
+// The content of the commit with the signature header, after the others
+func withSignature(content string, header string, signature string) string {
+	headers, message, _ := strings.Cut(content, "\n\n")
+	signature = strings.ReplaceAll(strings.TrimSuffix(signature, "\n"), "\n", "\n ")
+	return headers + "\n" + header + " " + signature + "\n\n" + message
+}
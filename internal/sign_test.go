@@ -0,0 +1,75 @@
+/* SPDX-License-Identifier: MIT */
+package internal
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResignCommits(t *testing.T) {
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen is needed to sign the commits")
+	}
+
+	dir := createTempDir(t, "git-decent-test-sign")
+	key := filepath.Join(dir, "key")
+	require.NoError(t, exec.Command("ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-C", "test", "-f", key).Run())
+	public, err := os.ReadFile(key + ".pub")
+	require.NoError(t, err)
+	signers := filepath.Join(dir, "allowed_signers")
+	require.NoError(t, os.WriteFile(signers, []byte("test@gitdecent.io "+string(public)), 0600))
+
+	repo := NewRepositoryBuilder(t).WithRandomCommits(1).MustBuild()
+	require.NoError(t, repo.SetConfig("gpg.format", "ssh"))
+	require.NoError(t, repo.SetConfig("user.signingkey", key))
+	require.NoError(t, repo.SetConfig("gpg.ssh.allowedSignersFile", signers))
+	require.NoError(t, repo.SetConfig("commit.gpgsign", "true"))
+	for k := 0; k < 2; k++ {
+		c, err := NewFixtureCommit(repo)
+		require.NoError(t, err)
+		require.NoError(t, repo.Commit(c))
+	}
+
+	log, err := repo.Log()
+	require.NoError(t, err)
+	for k := range log {
+		log[k].Date = time.Date(2022, 02, k+1, 9, 0, 0, 0, log[k].Date.Location())
+	}
+	require.NoError(t, repo.AmendDates(log))
+
+	amended, err := repo.Log()
+	require.NoError(t, err)
+	for k, commit := range amended {
+		assert.Equal(t, time.Date(2022, 02, k+1, 9, 0, 0, 0, commit.Date.Location()), commit.Date)
+	}
+	_, err = repo.command("verify-commit", "HEAD", "HEAD^")
+	assert.NoError(t, err, "signed commits are signed again")
+	root, err := repo.command("cat-file", "commit", amended[0].Hash)
+	require.NoError(t, err)
+	assert.NotContains(t, root, "gpgsig", "commits not signed stay so")
+
+	t.Run("Without key", func(t *testing.T) {
+		require.NoError(t, repo.SetConfig("user.signingkey", filepath.Join(dir, "missing")))
+		amended[2].Date = time.Date(2022, 02, 10, 9, 0, 0, 0, amended[2].Date.Location())
+		err := repo.AmendDates(amended[2:])
+		assert.ErrorContains(t, err, "can't be signed again")
+
+		head, err := repo.command("rev-parse", "HEAD")
+		require.NoError(t, err)
+		assert.Equal(t, amended[2].Hash, strings.TrimSpace(head), "HEAD is left as it was")
+	})
+}
+
+func TestWithSignature(t *testing.T) {
+	content := withSignature("tree t\nauthor A <a@b> 1 +0000\n\nmessage\n", "gpgsig", "-----BEGIN-----\nsignature\n-----END-----\n")
+	assert.Equal(t, "tree t\nauthor A <a@b> 1 +0000\ngpgsig -----BEGIN-----\n signature\n -----END-----\n\nmessage\n", content)
+	assert.Equal(t, "gpgsig", parseCommitObject(content).signature())
+	assert.Equal(t, "", parseCommitObject("tree t\n\nmessage\n").signature())
+}
@@ -1,6 +1,11 @@
 package internal
 
-import "math/rand"
+import (
+	"math/rand"
+	"time"
+
+	"github.com/afiestas/git-decent/config"
+)
 
 var testRandom bool = false
 
@@ -11,3 +16,34 @@ func randomMinute() int {
 
 	return rand.Intn(10)
 }
+
+// Minutes of noise, from the minute the commit was created when no noise
+// model is configured since it is already humanly random
+func noiseMinutes(date time.Time, noise *config.Noise) int {
+	if noise == nil {
+		if minute := date.Minute() % 10; minute != 0 {
+			return minute
+		}
+		return randomMinute()
+	}
+
+	if testRandom {
+		return (noise.Min + noise.Max) / 2
+	}
+	if !noise.Normal {
+		return noise.Min + rand.Intn(noise.Max-noise.Min+1)
+	}
+	//Most of the normal distribution falls within two deviations of the middle
+	middle := float64(noise.Min+noise.Max) / 2
+	deviation := float64(noise.Max-noise.Min) / 4
+	minutes := int(middle + rand.NormFloat64()*deviation + 0.5)
+	return min(max(minutes, noise.Min), noise.Max)
+}
+
+func randomSeconds(most time.Duration) time.Duration {
+	if testRandom {
+		return most / 2
+	}
+
+	return time.Duration(rand.Int63n(int64(most/time.Second)+1)) * time.Second
+}
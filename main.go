@@ -2,6 +2,8 @@
 package main
 
 import (
+	_ "time/tzdata"
+
 	"github.com/afiestas/git-decent/cmd"
 )
 
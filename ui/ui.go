@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 	"text/template"
 	"time"
@@ -112,18 +113,157 @@ func YesNoQuestion(question string) (bool, error) {
 }
 
 func PrintSchedule(schedule config.Schedule) {
+	if schedule.LeavesCommits() {
+		fmt.Printf("🚫 %-10s %s\n", "Disabled:", "commits of this remote and branch are left as they are")
+	} else if schedule.Disabled {
+		fmt.Printf("🚫 %-10s %s\n", "Disabled:", "only the team policy applies to this remote and branch")
+	}
+	if schedule.Preset != "" {
+		fmt.Printf("🧩 %-10s %s\n", "Preset:", schedule.Preset)
+	}
+	if schedule.Strategy != "" {
+		fmt.Printf("🧭 %-10s %s\n", "Strategy:", schedule.Strategy)
+	}
+	if schedule.Capacity != nil {
+		fmt.Printf("🚦 %-10s %s\n", "Capacity:", schedule.Capacity)
+	}
+	if schedule.MinGap > 0 {
+		fmt.Printf("🚦 %-10s %d minutes between commits\n", "Min gap:", int(schedule.MinGap.Minutes()))
+	}
+	if schedule.Threshold > 0 {
+		fmt.Printf("🎲 %-10s intervals under %d minutes are kept\n", "Threshold:", int(schedule.Threshold.Minutes()))
+	}
+	if schedule.Noise != nil {
+		fmt.Printf("🎲 %-10s %s\n", "Noise:", schedule.Noise)
+	}
+	if schedule.Jitter > 0 {
+		fmt.Printf("🎲 %-10s up to %d seconds\n", "Jitter:", int(schedule.Jitter.Seconds()))
+	}
+	if schedule.Location != nil {
+		fmt.Printf("🌍 %-10s %s\n", "Timezone:", schedule.Location)
+	}
 	for x := time.Monday; x <= time.Saturday; x++ {
-		s := schedule.Days[x].DecentFrames.String()
-		if len(s) == 0 {
-			s = "↪️ " + schedule.Days[x].ClosestDecentDay.String()
+		fmt.Printf("📅 %-10s %s\n", x.String()+":", dayString(schedule.Days[x]))
+	}
+	fmt.Printf("📅 %-10s %s\n", time.Sunday.String()+":", dayString(schedule.Days[time.Sunday]))
+
+	if schedule.Holidays != nil {
+		fmt.Printf("🎉 %-10s %s\n", "Holidays:", strings.Join(schedule.Holidays.Regions, ", "))
+	}
+
+	for _, off := range schedule.Off {
+		fmt.Printf("🏖️ %-10s %s\n", "Off:", off)
+	}
+
+	printDateFrames("✏️", schedule.Overrides)
+	printDateFrames("⛔", schedule.Blocks)
+
+	for _, rule := range schedule.Rules {
+		fmt.Printf("🔁 %-10s %s\n", rule.Name+":", rule.Raw())
+	}
+}
+
+// PrintOrigins prints the decent frames of each weekday followed by the keys
+// they come from, personal ones with their git config scope and the team
+// policy ones with its file
+func PrintOrigins(schedule config.Schedule, options map[string]string, scopes map[string]string, policy map[string]string) {
+	keys := config.DayKeys(options)
+	policyKeys := config.DayKeys(policy)
+	for _, d := range []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday, time.Sunday} {
+		frames := schedule.WeekFrames(d).String()
+		if frames == "" {
+			frames = SoftStyle.Styled("no decent frames")
+		}
+		fmt.Printf("📅 %-10s %s\n", d.String()+":", frames)
+		switch key, exists := keys[d]; {
+		case exists:
+			printOrigin(scopes[key], key, options[key])
+		case schedule.DayOrigin(d) == "preset":
+			printOrigin(scopes["preset"], "preset", schedule.Preset)
+		case schedule.DayOrigin(d) == "calendar":
+			printOrigin(scopes["icsfile"], "icsfile", schedule.IcsFile)
+		}
+
+		if schedule.Policy == nil {
+			continue
+		}
+		switch key, exists := policyKeys[d]; {
+		case exists:
+			printOrigin(config.PolicyFile, key, policy[key])
+		case schedule.Policy.DayOrigin(d) == "preset":
+			printOrigin(config.PolicyFile, "preset", schedule.Policy.Preset)
+		case schedule.Policy.DayOrigin(d) == "calendar":
+			printOrigin(config.PolicyFile, "icsfile", schedule.Policy.IcsFile)
+		default:
+			printOrigin(config.PolicyFile, "", "no decent frames")
+		}
+	}
+
+	printOtherOrigins(options, func(key string) string { return scopes[key] })
+	printOtherOrigins(policy, func(key string) string { return config.PolicyFile })
+}
+
+func printOrigin(origin string, key string, value string) {
+	if key != "" {
+		value = key + " = " + value
+	}
+	fmt.Printf("   %s %s\n", SoftStyle.Styled(fmt.Sprintf("%-12s", origin)), value)
+}
+
+func printOtherOrigins(options map[string]string, origin func(key string) string) {
+	keys := []string{}
+	for key := range options {
+		if !config.IsDayKey(key) {
+			keys = append(keys, key)
 		}
-		fmt.Printf("📅 %-10s %s\n", x.String()+":", s)
 	}
-	s := schedule.Days[0].DecentFrames.String()
-	if len(s) == 0 {
-		s = "↪️ " + schedule.Days[0].ClosestDecentDay.String()
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Printf("⚙️  %-10s %s %s\n", key+":", options[key], SoftStyle.Styled("("+origin(key)+")"))
+	}
+}
+
+func dayString(day config.Day) string {
+	s := day.DecentFrames.String()
+	if len(day.SpilloverFrames) > 0 {
+		spill := "🌙 " + day.SpilloverFrames.String()
+		if len(s) > 0 {
+			s = spill + " | " + s
+		} else {
+			s = spill
+		}
+	}
+	if len(day.DecentFrames) == 0 {
+		if len(s) > 0 {
+			s += " "
+		}
+		s += "↪️ " + day.ClosestDecentDay.String()
+	}
+	return s
+}
+
+func printDateFrames(icon string, dateFrames map[config.Date]config.TimeFrames) {
+	dates := make([]config.Date, 0, len(dateFrames))
+	for date := range dateFrames {
+		dates = append(dates, date)
+	}
+	sort.Slice(dates, func(i, j int) bool {
+		return dates[i].Before(dates[j])
+	})
+	for _, date := range dates {
+		fmt.Printf("%s %-10s %s\n", icon, date.String()+":", dateFrames[date])
+	}
+}
+
+func PrintHolidays(holidays []config.Holiday) {
+	for _, h := range holidays {
+		fmt.Printf("🎉 %s %s %s %s\n",
+			h.Date,
+			h.Date.Weekday().String()[:3],
+			PrimaryStyle.Styled(h.Name),
+			SoftStyle.Styled("("+h.Region+")"),
+		)
 	}
-	fmt.Printf("📅 %-10s %s\n", time.Sunday.String()+":", s)
 }
 
 func PrintAmend(before time.Time, after time.Time, msg string) {
@@ -146,7 +286,7 @@ func PrintAmend(before time.Time, after time.Time, msg string) {
 		day,
 		timeStr,
 	)
-	if after == before {
+	if config.SameDate(after, before) {
 		fmt.Printf("✅")
 	} else {
 		day := after.Format("Mon")